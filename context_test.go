@@ -0,0 +1,74 @@
+package word_index
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIndexContext(t *testing.T) {
+	indexes := map[string]IndexContext{
+		`bin`:         NewIndex().(IndexContext),
+		`sync`:        NewIndexSync().(IndexContext),
+		`sharded`:     NewIndexSharded(4).(IndexContext),
+		`matrix`:      NewMatrixIndex(),
+		`matrix sync`: NewMatrixIndexSync(),
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, index := range indexes {
+		index.Add(documents...)
+
+		want := index.FindAll(`docker cont*`)
+		got, err := index.FindAllContext(context.Background(), `docker cont*`)
+		if err != nil {
+			t.Fatalf(`%s: %s`, name, err.Error())
+		}
+		if len(want) == 0 || len(want) != len(got) {
+			t.Fatalf(`%s: len not equals %d, %d`, name, len(want), len(got))
+		}
+		i, err := index.FindContext(context.Background(), `docker`)
+		if err != nil || i != index.Find(`docker`) {
+			t.Fatalf(`%s: FindContext differs from Find`, name)
+		}
+		i, err = index.FindOffContext(context.Background(), `docker`, want[0])
+		if err != nil || i != want[0] {
+			t.Fatalf(`%s: FindOffContext differs from FindOff`, name)
+		}
+
+		if _, err = index.FindAllContext(canceled, `docker cont*`); err != context.Canceled {
+			t.Fatalf(`%s: expected context.Canceled, %v`, name, err)
+		}
+		if _, err = index.FindContext(canceled, `docker`); err != context.Canceled {
+			t.Fatalf(`%s: expected context.Canceled, %v`, name, err)
+		}
+	}
+
+	m := NewMatrixIndex()
+	m.Fit(documents...)
+	if _, err := m.QueryAndOrContext(canceled, `docker the`, true); err != context.Canceled {
+		t.Fatalf(`expected context.Canceled, %v`, err)
+	}
+	result, err := m.QueryAndOrContext(context.Background(), `docker the`, true)
+	if err != nil || len(result) != len(m.QueryAndOr(`docker the`, true)) {
+		t.Fatalf(`QueryAndOrContext differs from QueryAndOr`)
+	}
+}
+
+func TestIndexVector_SearchNeighborhoodContext(t *testing.T) {
+	iv, _ := NewIndexVector()
+	if err := iv.Fit(randomVectors(1000, 3, 14)); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	list, err := iv.SearchNeighborhoodContext(context.Background(), []float64{0.5, 0.5, 0.5}, []float64{0.2, 0.2, 0.2})
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(list) == 0 {
+		t.Fatalf(`empty result`)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = iv.SearchNeighborhoodContext(canceled, []float64{0.5, 0.5, 0.5}, []float64{0.2, 0.2, 0.2}); err != context.Canceled {
+		t.Fatalf(`expected context.Canceled, %v`, err)
+	}
+}
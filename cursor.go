@@ -0,0 +1,250 @@
+package word_index
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"strings"
+)
+
+// Cursor iterates ids of matched documents in ascending order,
+// ids are computed lazily on Next.
+type Cursor interface {
+	// Next returns the next matched id, false when there are no more ids.
+	Next() (int, bool)
+	// Seek skips ids less than id, cursor never moves backwards.
+	Seek(id int)
+	// Close releases the cursor, Next returns false after Close.
+	Close()
+}
+
+// IndexCursor is implemented by indexes returning lazy cursors over matched documents.
+type IndexCursor interface {
+	Index
+	Cursor(string) Cursor
+}
+
+// postingsCursor iterates one ordered postings list.
+type postingsCursor struct {
+	postings []int
+	offset   int
+}
+
+func (c *postingsCursor) Next() (int, bool) {
+	if c.offset >= len(c.postings) {
+		return emptyFind, false
+	}
+	c.offset++
+	return c.postings[c.offset-1], true
+}
+
+func (c *postingsCursor) peek() (int, bool) {
+	if c.offset >= len(c.postings) {
+		return emptyFind, false
+	}
+	return c.postings[c.offset], true
+}
+
+func (c *postingsCursor) Seek(id int) {
+	rest := c.postings[c.offset:]
+	c.offset += sort.SearchInts(rest, id)
+}
+
+func (c *postingsCursor) Close() {
+	c.postings, c.offset = nil, 0
+}
+
+// unionCursor merges postings lists by a min-heap of their heads.
+type unionCursor struct {
+	lists []*postingsCursor
+	last  int
+}
+
+func newUnionCursor(postings [][]int) *unionCursor {
+	c := &unionCursor{lists: make([]*postingsCursor, 0, len(postings)), last: emptyFind}
+	for _, p := range postings {
+		if len(p) > 0 {
+			c.lists = append(c.lists, &postingsCursor{postings: p})
+		}
+	}
+	heap.Init(c)
+	return c
+}
+
+func (c *unionCursor) Len() int { return len(c.lists) }
+func (c *unionCursor) Less(i, j int) bool {
+	a, _ := c.lists[i].peek()
+	b, _ := c.lists[j].peek()
+	return a < b
+}
+func (c *unionCursor) Swap(i, j int)      { c.lists[i], c.lists[j] = c.lists[j], c.lists[i] }
+func (c *unionCursor) Push(x interface{}) { c.lists = append(c.lists, x.(*postingsCursor)) }
+func (c *unionCursor) Pop() interface{} {
+	x := c.lists[len(c.lists)-1]
+	c.lists = c.lists[:len(c.lists)-1]
+	return x
+}
+
+func (c *unionCursor) Next() (int, bool) {
+	id, ok := c.peek()
+	if ok {
+		c.last = id
+	}
+	return id, ok
+}
+
+// peek returns the next id without moving the cursor, heads already returned are skipped.
+func (c *unionCursor) peek() (int, bool) {
+	for len(c.lists) > 0 {
+		id, _ := c.lists[0].peek()
+		if id > c.last {
+			return id, true
+		}
+		c.lists[0].Next()
+		if _, ok := c.lists[0].peek(); ok {
+			heap.Fix(c, 0)
+		} else {
+			heap.Pop(c)
+		}
+	}
+	return emptyFind, false
+}
+
+func (c *unionCursor) Seek(id int) {
+	lists := c.lists[:0]
+	for _, l := range c.lists {
+		l.Seek(id)
+		if _, ok := l.peek(); ok {
+			lists = append(lists, l)
+		}
+	}
+	c.lists = lists
+	heap.Init(c)
+}
+
+func (c *unionCursor) Close() {
+	c.lists = nil
+}
+
+// intersectCursor returns ids present in every cursor by leapfrog seeking.
+type intersectCursor struct {
+	cursors []*unionCursor
+	closed  bool
+}
+
+func (c *intersectCursor) Next() (int, bool) {
+	if c.closed || len(c.cursors) == 0 {
+		return emptyFind, false
+	}
+	id, ok := c.cursors[0].peek()
+	for ok {
+		matched := true
+		for _, cursor := range c.cursors {
+			cursor.Seek(id)
+			next, ok := cursor.peek()
+			if !ok {
+				c.closed = true
+				return emptyFind, false
+			}
+			if next > id {
+				id, matched = next, false
+				break
+			}
+		}
+		if matched {
+			for _, cursor := range c.cursors {
+				cursor.Next()
+			}
+			return id, true
+		}
+	}
+	c.closed = true
+	return emptyFind, false
+}
+
+func (c *intersectCursor) Seek(id int) {
+	for _, cursor := range c.cursors {
+		cursor.Seek(id)
+	}
+}
+
+func (c *intersectCursor) Close() {
+	for _, cursor := range c.cursors {
+		cursor.Close()
+	}
+	c.closed = true
+}
+
+// Cursor returns lazy cursor over documents matching any word of query.
+func (m *MatrixIndex) Cursor(query string) Cursor {
+	return m.CursorAndOr(query, false)
+}
+
+// CursorAndOr is the lazy version of QueryAndOr, postings of dictionary terms are
+// merged only as far as Next is called.
+func (m *MatrixIndex) CursorAndOr(query string, useAnd bool) Cursor {
+	words := strings.Split(strings.ToLower(query), ` `)
+	cursors := make([]*unionCursor, len(words))
+	postings := make([][]int, 0)
+	for i, word := range words {
+		q, variants := makeVariants(word)
+		terms := m.findTerms(q, variants)
+		if useAnd {
+			cursors[i] = newUnionCursor(terms)
+		} else {
+			postings = append(postings, terms...)
+		}
+	}
+	if useAnd {
+		return &intersectCursor{cursors: cursors}
+	}
+	return newUnionCursor(postings)
+}
+
+// indexWordCursor scans documents of indexWord from the current offset on every Next.
+type indexWordCursor struct {
+	index    *indexWord
+	variants []*variant
+	offset   int
+}
+
+func (c *indexWordCursor) Next() (int, bool) {
+	if c.index == nil {
+		return emptyFind, false
+	}
+	id, _ := c.index.findRange(context.Background(), c.variants, c.offset, len(c.index.data))
+	if id == emptyFind {
+		c.offset = len(c.index.data)
+		return emptyFind, false
+	}
+	c.offset = id + 1
+	return id, true
+}
+
+func (c *indexWordCursor) Seek(id int) {
+	if id > c.offset {
+		c.offset = id
+	}
+}
+
+func (c *indexWordCursor) Close() {
+	c.index, c.variants = nil, nil
+}
+
+// Cursor returns lazy cursor over documents matching query.
+func (i *indexWord) Cursor(query string) Cursor {
+	return &indexWordCursor{index: i, variants: i.queryVariants(query)}
+}
+
+// Cursor returns cursor over the current snapshot, documents added later are not visible.
+func (i *indexWordSync) Cursor(query string) Cursor {
+	return i.snapshot().Cursor(query)
+}
+
+func (m *MatrixIndexSync) Cursor(query string) Cursor {
+	return m.snapshot().Cursor(query)
+}
+
+func (m *MatrixIndexSync) CursorAndOr(query string, useAnd bool) Cursor {
+	return m.snapshot().CursorAndOr(query, useAnd)
+}
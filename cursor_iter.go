@@ -0,0 +1,21 @@
+//go:build go1.23
+// +build go1.23
+
+package word_index
+
+import (
+	"iter"
+)
+
+// CursorSeq adapts cursor to iter.Seq, the cursor is closed when iteration ends.
+func CursorSeq(c Cursor) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		defer c.Close()
+		for {
+			id, ok := c.Next()
+			if !ok || !yield(id) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+// +build go1.23
+
+package word_index
+
+import (
+	"testing"
+)
+
+func TestCursorSeq(t *testing.T) {
+	matrix := NewMatrixIndex()
+	matrix.Fit(documents...)
+	want := matrix.Query(`the`)
+	n := 0
+	for id := range CursorSeq(matrix.Cursor(`the`)) {
+		if id != want[n] {
+			t.Fatalf(`%d != %d`, id, want[n])
+		}
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Fatalf(`len not equals 3, %d`, n)
+	}
+}
@@ -0,0 +1,94 @@
+package word_index
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var (
+	_ IndexCursor = (*MatrixIndex)(nil)
+	_ IndexCursor = (*MatrixIndexSync)(nil)
+)
+
+func TestCursor(t *testing.T) {
+	matrix := NewMatrixIndex()
+	matrix.Fit(documents...)
+	indexes := []Index{NewIndex(), NewIndexSync(), NewIndexSharded(3), NewMatrixIndexSync()}
+	for _, index := range indexes {
+		index.Add(documents...)
+	}
+
+	for _, query := range []string{`docker`, `the a`, `c* docker`, `мер(ы|а) the`, `unknown`} {
+		want := matrix.Query(query)
+		tCursor(t, query, matrix.Cursor(query), want)
+		for _, index := range indexes {
+			c, ok := index.(IndexCursor)
+			if !ok {
+				t.Fatalf(`%T must implement IndexCursor`, index)
+			}
+			tCursor(t, query, c.Cursor(query), index.FindAll(query))
+		}
+
+		want = matrix.QueryAndOr(query, true)
+		tCursor(t, query, matrix.CursorAndOr(query, true), want)
+	}
+}
+
+func tCursor(t *testing.T, query string, c Cursor, want []int) {
+	got := make([]int, 0)
+	for id, ok := c.Next(); ok; id, ok = c.Next() {
+		got = append(got, id)
+	}
+	if len(got) != len(want) {
+		t.Fatalf(`%s: len not equals %d, %d`, query, len(want), len(got))
+	}
+	for i := range want {
+		if want[i] != got[i] {
+			t.Fatalf(`%s: %v != %v`, query, got, want)
+		}
+	}
+	c.Close()
+	if _, ok := c.Next(); ok {
+		t.Fatalf(`%s: closed cursor returns id`, query)
+	}
+}
+
+func TestCursor_Seek(t *testing.T) {
+	rnd := rand.New(rand.NewSource(15))
+	words := []string{`aa`, `bb`, `cc`, `dd`}
+	docs := make([]string, 2000)
+	for i := range docs {
+		docs[i] = words[rnd.Intn(4)] + ` ` + words[rnd.Intn(4)] + ` x`
+	}
+	matrix := NewMatrixIndex()
+	matrix.Fit(docs...)
+
+	for _, useAnd := range []bool{false, true} {
+		all := matrix.QueryAndOr(`aa bb`, useAnd)
+		c := matrix.CursorAndOr(`aa bb`, useAnd)
+		c.Seek(1000)
+		id, ok := c.Next()
+		expected := emptyFind
+		for _, x := range all {
+			if x >= 1000 {
+				expected = x
+				break
+			}
+		}
+		if !ok || id != expected {
+			t.Fatalf(`and %v: seek returns %d, expected %d`, useAnd, id, expected)
+		}
+		c.Seek(10)
+		if next, _ := c.Next(); next <= id {
+			t.Fatalf(`and %v: cursor moved backwards`, useAnd)
+		}
+	}
+
+	bin := NewIndex()
+	bin.Add(docs...)
+	c := bin.(IndexCursor).Cursor(`aa`)
+	c.Seek(1500)
+	if id, ok := c.Next(); !ok || id < 1500 || id != bin.FindOff(`aa`, 1500) {
+		t.Fatalf(`seek returns %d`, id)
+	}
+}
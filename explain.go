@@ -0,0 +1,93 @@
+package word_index
+
+import (
+	"sort"
+	"strings"
+)
+
+// Explanation describes how document matched query of MatrixIndex.
+type Explanation struct {
+	Query string
+	Id    int
+	// Matched is true if any clause matched as in Query, MatchedAll if every clause matched as in QueryAndOr with and.
+	Matched    bool
+	MatchedAll bool
+	// Score is the QueryRanked score of document, sum of Boost*Idf of matched clauses and Static.
+	Score   float64
+	Static  float64
+	Clauses []ClauseExplanation
+}
+
+// ClauseExplanation is a query word with dictionary terms it expanded to.
+type ClauseExplanation struct {
+	Clause   string
+	Variants []string
+	Prefix   bool
+	Boost    float64
+	Terms    []TermExplanation
+	Matched  bool
+	// DocFreq is the number of documents matched by clause, Idf is its score component.
+	DocFreq int
+	Idf     float64
+}
+
+// TermExplanation is a dictionary term, Matched is true if document contains it.
+type TermExplanation struct {
+	Term    string
+	DocFreq int
+	Matched bool
+}
+
+// Explain returns which clauses of query matched document id, dictionary terms
+// they expanded to and score components of QueryRanked.
+func (m *MatrixIndex) Explain(query string, id int) (*Explanation, error) {
+	if id < 0 || id >= len(m.documents) {
+		return nil, ErrDocumentNotFound
+	}
+	e := &Explanation{Query: query, Id: id, MatchedAll: true}
+	for _, word := range strings.Split(strings.ToLower(query), ` `) {
+		word, boost := m.boost(word)
+		q, variants := makeVariants(word)
+		clause := ClauseExplanation{
+			Clause:   word,
+			Variants: variants,
+			Prefix:   len(q) > 0 && q[len(q)-1] == tagAnyRune,
+			Boost:    boost,
+			Terms:    make([]TermExplanation, 0),
+		}
+		postings := make([][]int, 0)
+		if len(strings.TrimSpace(q)) >= 2 {
+			for n := m.lowerBoundTerm(q, 0, len(m.items)-1); n < len(m.items) && m.compareWord(m.items[n].word, q, variants); n++ {
+				item := m.items[n]
+				k := sort.SearchInts(item.index, id)
+				term := TermExplanation{
+					Term:    item.word,
+					DocFreq: len(item.index),
+					Matched: k < len(item.index) && item.index[k] == id,
+				}
+				clause.Matched = clause.Matched || term.Matched
+				clause.Terms = append(clause.Terms, term)
+				postings = append(postings, item.index)
+			}
+		}
+		if clause.DocFreq = len(MergeOrderedArray(postings)); clause.DocFreq > 0 {
+			clause.Idf = m.idf(clause.DocFreq)
+		}
+		if clause.Matched {
+			e.Matched = true
+			e.Score += clause.Boost * clause.Idf
+		} else {
+			e.MatchedAll = false
+		}
+		e.Clauses = append(e.Clauses, clause)
+	}
+	if e.Matched {
+		e.Static = m.staticScore(id)
+		e.Score += e.Static
+	}
+	return e, nil
+}
+
+func (m *MatrixIndexSync) Explain(query string, id int) (*Explanation, error) {
+	return m.snapshot().Explain(query, id)
+}
@@ -0,0 +1,46 @@
+package word_index
+
+import "testing"
+
+func TestMatrixIndex_Explain(t *testing.T) {
+	index := NewMatrixIndex()
+	index.Fit(
+		`docker compose file`,
+		`dockers run containers`,
+		`kubernetes pods`,
+	)
+
+	e, err := index.Explain(`docker(s|ed) contain* helm`, 1)
+	if err != nil {
+		t.Fatalf(`error explain: %v`, err)
+	}
+	if !e.Matched || e.MatchedAll || len(e.Clauses) != 3 {
+		t.Fatalf(`wrong explanation %v`, e)
+	}
+
+	variant := e.Clauses[0]
+	if !variant.Matched || len(variant.Variants) != 3 || len(variant.Terms) != 2 || variant.DocFreq != 2 {
+		t.Fatalf(`wrong variant clause %v`, variant)
+	}
+	if variant.Terms[0].Term != `docker` || variant.Terms[0].Matched || !variant.Terms[1].Matched {
+		t.Fatalf(`wrong variant terms %v`, variant.Terms)
+	}
+
+	prefix := e.Clauses[1]
+	if !prefix.Prefix || !prefix.Matched || len(prefix.Terms) != 1 || prefix.Terms[0].Term != `containers` {
+		t.Fatalf(`wrong prefix clause %v`, prefix)
+	}
+	if e.Clauses[2].Matched || len(e.Clauses[2].Terms) != 0 || e.Clauses[2].Idf != 0 {
+		t.Fatalf(`wrong unmatched clause %v`, e.Clauses[2])
+	}
+
+	for _, ranked := range index.QueryRanked(`docker(s|ed) contain* helm`) {
+		if ranked.Id == 1 && ranked.Score != e.Score {
+			t.Fatalf(`score %f not equals ranked score %f`, e.Score, ranked.Score)
+		}
+	}
+
+	if _, err := index.Explain(`docker`, 3); err != ErrDocumentNotFound {
+		t.Fatalf(`expected ErrDocumentNotFound, %v`, err)
+	}
+}
@@ -0,0 +1,144 @@
+//go:build go1.18
+// +build go1.18
+
+package word_index
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrDuplicateID   = errors.New(`document id already exists`)
+	ErrInvalidBatch  = errors.New(`number of ids and documents differs`)
+	ErrIndexNotEmpty = errors.New(`index already has documents`)
+)
+
+// IndexExternal maps caller supplied document ids, e.g. string or uint64 keys,
+// to dense ordinals of the wrapped Index, which are positions of added documents.
+// It is safe for concurrent use if the wrapped index is.
+type IndexExternal[K comparable] struct {
+	index    Index
+	mx       sync.RWMutex
+	ids      []K
+	ordinals map[K]int
+}
+
+// Add adds document with id.
+func (e *IndexExternal[K]) Add(id K, document string) error {
+	return e.AddBatch([]K{id}, document)
+}
+
+// AddBatch adds documents with ids of the same length, nothing is added on error.
+func (e *IndexExternal[K]) AddBatch(ids []K, documents ...string) error {
+	if len(ids) != len(documents) {
+		return ErrInvalidBatch
+	}
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	batch := make(map[K]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := e.ordinals[id]; ok {
+			return ErrDuplicateID
+		}
+		if _, ok := batch[id]; ok {
+			return ErrDuplicateID
+		}
+		batch[id] = struct{}{}
+	}
+	for _, id := range ids {
+		e.ordinals[id] = len(e.ids)
+		e.ids = append(e.ids, id)
+	}
+	e.index.Add(documents...)
+	return nil
+}
+
+// Find returns id of the first document matching query.
+func (e *IndexExternal[K]) Find(query string) (K, bool) {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+	return e.id(e.index.Find(query))
+}
+
+// FindOff returns id of the first document matching query added not before document from.
+func (e *IndexExternal[K]) FindOff(query string, from K) (K, bool) {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+	ordinal, ok := e.ordinals[from]
+	if !ok {
+		var zero K
+		return zero, false
+	}
+	return e.id(e.index.FindOff(query, ordinal))
+}
+
+// FindAll returns ids of documents matching query in order of addition.
+func (e *IndexExternal[K]) FindAll(query string) []K {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+	ordinals := e.index.FindAll(query)
+	ids := make([]K, 0, len(ordinals))
+	for _, ordinal := range ordinals {
+		if id, ok := e.id(ordinal); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// FindAt reports whether document id matches query.
+func (e *IndexExternal[K]) FindAt(id K, query string) bool {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+	ordinal, ok := e.ordinals[id]
+	return ok && e.index.FindAt(ordinal, query)
+}
+
+// DocumentByID returns document with id.
+func (e *IndexExternal[K]) DocumentByID(id K) (string, bool) {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+	ordinal, ok := e.ordinals[id]
+	if !ok {
+		return ``, false
+	}
+	return e.index.DocumentAt(ordinal)
+}
+
+// Ordinal returns position of document id in the wrapped index.
+func (e *IndexExternal[K]) Ordinal(id K) (int, bool) {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+	ordinal, ok := e.ordinals[id]
+	return ordinal, ok
+}
+
+// ID returns id of document at position ordinal of the wrapped index.
+func (e *IndexExternal[K]) ID(ordinal int) (K, bool) {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+	return e.id(ordinal)
+}
+
+func (e *IndexExternal[K]) id(ordinal int) (K, bool) {
+	if ordinal < 0 || ordinal >= len(e.ids) {
+		var zero K
+		return zero, false
+	}
+	return e.ids[ordinal], true
+}
+
+// Index returns the wrapped index, documents must be added only by IndexExternal.
+func (e *IndexExternal[K]) Index() Index {
+	return e.index
+}
+
+// NewIndexExternal wraps index with caller supplied document ids of type K,
+// index must be empty so ordinals start at 0.
+func NewIndexExternal[K comparable](index Index) (*IndexExternal[K], error) {
+	if _, ok := index.DocumentAt(0); ok {
+		return nil, ErrIndexNotEmpty
+	}
+	return &IndexExternal[K]{index: index, ids: make([]K, 0), ordinals: make(map[K]int)}, nil
+}
@@ -0,0 +1,80 @@
+//go:build go1.18
+// +build go1.18
+
+package word_index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIndexExternal(t *testing.T) {
+	for name, index := range map[string]Index{
+		`bin`:    NewIndex(),
+		`sync`:   NewIndexSync(),
+		`matrix`: NewMatrixIndex(),
+	} {
+		e, err := NewIndexExternal[string](index)
+		if err != nil {
+			t.Fatalf(`%s: error create: %v`, name, err)
+		}
+		if err := e.Add(`doc-a`, `docker images`); err != nil {
+			t.Fatalf(`%s: error add: %v`, name, err)
+		}
+		if err := e.AddBatch([]string{`doc-b`, `doc-c`}, `kubernetes pods`, `docker compose`); err != nil {
+			t.Fatalf(`%s: error add batch: %v`, name, err)
+		}
+
+		if ids := e.FindAll(`docker`); !reflect.DeepEqual(ids, []string{`doc-a`, `doc-c`}) {
+			t.Fatalf(`%s: wrong ids %v`, name, ids)
+		}
+		if id, ok := e.Find(`pods`); !ok || id != `doc-b` {
+			t.Fatalf(`%s: wrong id %v`, name, id)
+		}
+		if id, ok := e.FindOff(`docker`, `doc-c`); !ok || id != `doc-c` {
+			t.Fatalf(`%s: wrong FindOff id %v`, name, id)
+		}
+		if _, ok := e.FindOff(`docker`, `doc-x`); ok {
+			t.Fatalf(`%s: FindOff from unknown id must fail`, name)
+		}
+		if !e.FindAt(`doc-c`, `compose`) || e.FindAt(`doc-a`, `compose`) || e.FindAt(`doc-x`, `compose`) {
+			t.Fatalf(`%s: wrong FindAt`, name)
+		}
+		if d, ok := e.DocumentByID(`doc-b`); !ok || d != `kubernetes pods` {
+			t.Fatalf(`%s: wrong document %s`, name, d)
+		}
+		if ordinal, ok := e.Ordinal(`doc-c`); !ok || ordinal != 2 {
+			t.Fatalf(`%s: wrong ordinal %d`, name, ordinal)
+		}
+
+		if err := e.Add(`doc-a`, `duplicate`); err != ErrDuplicateID {
+			t.Fatalf(`%s: expected ErrDuplicateID, %v`, name, err)
+		}
+		if err := e.AddBatch([]string{`doc-d`, `doc-d`}, `a`, `b`); err != ErrDuplicateID {
+			t.Fatalf(`%s: expected ErrDuplicateID in batch, %v`, name, err)
+		}
+		if err := e.AddBatch([]string{`doc-d`}, `a`, `b`); err != ErrInvalidBatch {
+			t.Fatalf(`%s: expected ErrInvalidBatch, %v`, name, err)
+		}
+		if _, ok := e.DocumentByID(`doc-d`); ok {
+			t.Fatalf(`%s: failed batch must not be added`, name)
+		}
+	}
+}
+
+func TestIndexExternal_Uint64(t *testing.T) {
+	e, err := NewIndexExternal[uint64](NewIndex())
+	if err != nil {
+		t.Fatalf(`error create: %v`, err)
+	}
+	e.AddBatch([]uint64{42, 7}, `docker one`, `kube two`)
+	if id, ok := e.Find(`kube`); !ok || id != 7 {
+		t.Fatalf(`wrong id %d`, id)
+	}
+
+	index := NewIndex()
+	index.Add(`docker one`)
+	if _, err := NewIndexExternal[string](index); err != ErrIndexNotEmpty {
+		t.Fatalf(`expected ErrIndexNotEmpty, %v`, err)
+	}
+}
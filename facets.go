@@ -0,0 +1,88 @@
+package word_index
+
+import (
+	"sort"
+	"strings"
+)
+
+// FacetCount is the number of matched documents with keyword value.
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// keywordColumn keeps keyword value of every document as ordinal in dictionary of values,
+// so counting values over a document set reads only ordinals.
+type keywordColumn struct {
+	ords     []int
+	values   []string
+	ordinals map[string]int
+}
+
+func (k *keywordColumn) add(value string, ok bool) {
+	if !ok {
+		k.ords = append(k.ords, emptyFind)
+		return
+	}
+	ord, exists := k.ordinals[value]
+	if !exists {
+		ord = len(k.values)
+		k.ordinals[value] = ord
+		k.values = append(k.values, value)
+	}
+	k.ords = append(k.ords, ord)
+}
+
+func newKeywordColumn() *keywordColumn {
+	return &keywordColumn{ords: make([]int, 0), values: make([]string, 0), ordinals: make(map[string]int)}
+}
+
+// Facets counts values of keyword field over documents matching query as in Query,
+// empty query matches all documents. Counts are ordered by count then value,
+// topN <= 0 returns all values.
+func (f *FieldIndex) Facets(query, field string, topN int) ([]FacetCount, error) {
+	schema, ok := f.fields[field]
+	if !ok {
+		return nil, ErrUnknownField
+	}
+	if schema.Type != FieldKeyword {
+		return nil, ErrInvalidField
+	}
+	column := f.keywords[field]
+
+	counts := make([]int, len(column.values))
+	if strings.TrimSpace(query) == `` {
+		for _, ord := range column.ords {
+			if ord != emptyFind {
+				counts[ord]++
+			}
+		}
+	} else {
+		result, err := f.Query(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range result {
+			if ord := column.ords[id]; ord != emptyFind {
+				counts[ord]++
+			}
+		}
+	}
+
+	facets := make([]FacetCount, 0)
+	for ord, count := range counts {
+		if count > 0 {
+			facets = append(facets, FacetCount{Value: column.values[ord], Count: count})
+		}
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	if topN > 0 && len(facets) > topN {
+		facets = facets[:topN]
+	}
+	return facets, nil
+}
@@ -0,0 +1,77 @@
+package word_index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldIndex_Facets(t *testing.T) {
+	index, err := NewFieldIndex(
+		Field{Name: `title`, Indexed: true},
+		Field{Name: `category`, Type: FieldKeyword, Indexed: true, Stored: true},
+		Field{Name: `price`, Type: FieldNumber, Indexed: true},
+	)
+	if err != nil {
+		t.Fatalf(`error create field index: %v`, err)
+	}
+	index.Add(
+		NewDocument().SetField(`title`, `docker book`).SetField(`category`, `Books`).SetNumber(`price`, 10),
+		NewDocument().SetField(`title`, `docker course`).SetField(`category`, `Video Courses`).SetNumber(`price`, 30),
+		NewDocument().SetField(`title`, `docker in action`).SetField(`category`, `Books`).SetNumber(`price`, 50),
+		NewDocument().SetField(`title`, `helm book`).SetField(`category`, `Books`),
+		NewDocument().SetField(`title`, `docker stickers`),
+		NewDocument().SetField(`title`, `docker mug`).SetField(`category`, `Merch`),
+		NewDocument().SetField(`title`, `helm notes`).SetField(`category`, `Books Used`),
+	)
+
+	facets, err := index.Facets(`docker`, `category`, 0)
+	if err != nil {
+		t.Fatalf(`error facets: %v`, err)
+	}
+	expected := []FacetCount{{`Books`, 2}, {`Merch`, 1}, {`Video Courses`, 1}}
+	if !reflect.DeepEqual(facets, expected) {
+		t.Fatalf(`facets %v not equals %v`, facets, expected)
+	}
+
+	facets, _ = index.Facets(`docker price:[20 TO *]`, `category`, 1)
+	if !reflect.DeepEqual(facets, []FacetCount{{`Books`, 1}}) {
+		t.Fatalf(`wrong top facet %v`, facets)
+	}
+
+	facets, _ = index.Facets(``, `category`, 0)
+	if len(facets) != 4 || facets[0].Count != 3 {
+		t.Fatalf(`wrong facets of all documents %v`, facets)
+	}
+
+	result, _ := index.Query(`category:Books`)
+	if !reflect.DeepEqual(result, []int{0, 2, 3}) {
+		t.Fatalf(`keyword must match exact value %v`, result)
+	}
+	if result, _ := index.Query(`category:books`); len(result) != 0 {
+		t.Fatalf(`keyword must be case sensitive %v`, result)
+	}
+	if result, _ := index.Query(`category:"Video Courses"`); !reflect.DeepEqual(result, []int{1}) {
+		t.Fatalf(`quoted keyword must match value with spaces %v`, result)
+	}
+	if ranked, _ := index.QueryRanked(`docker category:"Video Courses"^2`); len(ranked) == 0 || ranked[0].Id != 1 {
+		t.Fatalf(`quoted keyword must be boosted %v`, ranked)
+	}
+	if facets, _ = index.Facets(`category:"Video Courses" category:Merch`, `category`, 0); len(facets) != 2 {
+		t.Fatalf(`wrong facets of quoted query %v`, facets)
+	}
+	for _, query := range []string{`category:"Books (Used)"`, `category:"B*"`, `category:B*`, `category:Book(s|z)`, `category:Video`} {
+		if result, _ := index.Query(query); len(result) != 0 {
+			t.Fatalf(`query %s: keyword must match equal value only %v`, query, result)
+		}
+	}
+	if _, err := index.Query(`category:"Video Courses`); err != ErrQuerySyntax {
+		t.Fatalf(`expected ErrQuerySyntax, %v`, err)
+	}
+
+	if _, err := index.Facets(`docker`, `title`, 0); err != ErrInvalidField {
+		t.Fatalf(`expected ErrInvalidField, %v`, err)
+	}
+	if _, err := index.Facets(`docker`, `brand`, 0); err != ErrUnknownField {
+		t.Fatalf(`expected ErrUnknownField, %v`, err)
+	}
+}
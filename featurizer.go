@@ -0,0 +1,122 @@
+package word_index
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidDimension = errors.New(`invalid vector dimension`)
+)
+
+// Featurizer turns a document into a vector, documents are tokenized as in MatrixIndex.Fit.
+type Featurizer interface {
+	Transform(document string) []float64
+}
+
+// Vectorize returns vectors of documents, Vector.Id is the document position
+// so the vectors share document ids with MatrixIndex fitted on the same documents.
+func Vectorize(f Featurizer, documents ...string) []*Vector {
+	result := make([]*Vector, len(documents))
+	for i, document := range documents {
+		result[i] = NewVector(uint32(i), f.Transform(document), nil)
+	}
+	return result
+}
+
+// TfIdfVectorizer maps documents to TF-IDF vectors over the vocabulary of MatrixIndex,
+// coordinate i corresponds to the i-th word of the index. Vectors are L2 normalized.
+// Vocabulary and idf are copied on creation, documents added to the index later do not
+// change dimension or coordinates of vectors.
+type TfIdfVectorizer struct {
+	words []string
+	idf   []float64
+}
+
+func (t *TfIdfVectorizer) Dim() int {
+	return len(t.words)
+}
+
+func (t *TfIdfVectorizer) Transform(document string) []float64 {
+	v := make([]float64, len(t.words))
+	for _, word := range strings.Split(strings.ToLower(document), ` `) {
+		if len(word) == 0 {
+			continue
+		}
+		if i := sort.SearchStrings(t.words, word); i < len(t.words) && t.words[i] == word {
+			v[i]++
+		}
+	}
+	for i, tf := range v {
+		if tf > 0 {
+			v[i] = tf * t.idf[i]
+		}
+	}
+	normalizeL2(v)
+	return v
+}
+
+func NewTfIdfVectorizer(index *MatrixIndex) *TfIdfVectorizer {
+	t := &TfIdfVectorizer{
+		words: make([]string, len(index.items)),
+		idf:   make([]float64, len(index.items)),
+	}
+	for i, item := range index.items {
+		t.words[i] = item.word
+		t.idf[i] = index.idf(len(item.index))
+	}
+	return t
+}
+
+// HashingVectorizer maps words to dim coordinates by hash, the sign of a word
+// is taken from another hash bit to reduce collisions bias. Vectors are L2 normalized.
+type HashingVectorizer struct {
+	dim int
+}
+
+func (h *HashingVectorizer) Dim() int {
+	return h.dim
+}
+
+func (h *HashingVectorizer) Transform(document string) []float64 {
+	v := make([]float64, h.dim)
+	for _, word := range strings.Split(strings.ToLower(document), ` `) {
+		if len(word) == 0 {
+			continue
+		}
+		hash := fnv.New64a()
+		hash.Write([]byte(word))
+		sum := hash.Sum64()
+		if sum>>63 == 1 {
+			v[(sum&math.MaxInt32)%uint64(h.dim)]--
+		} else {
+			v[(sum&math.MaxInt32)%uint64(h.dim)]++
+		}
+	}
+	normalizeL2(v)
+	return v
+}
+
+func NewHashingVectorizer(dim int) (*HashingVectorizer, error) {
+	if dim <= 0 {
+		return nil, ErrInvalidDimension
+	}
+	return &HashingVectorizer{dim: dim}, nil
+}
+
+func normalizeL2(v []float64) {
+	s := float64(0)
+	for _, x := range v {
+		s += x * x
+	}
+	if s == 0 {
+		return
+	}
+	s = math.Sqrt(s)
+	for i := range v {
+		v[i] /= s
+	}
+}
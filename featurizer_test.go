@@ -0,0 +1,80 @@
+package word_index
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestTfIdfVectorizer(t *testing.T) {
+	documents := []string{
+		`docker build image`,
+		`docker run container`,
+		`golang concurrency golang`,
+		`docker image build layers`,
+	}
+	index := NewMatrixIndex()
+	if err := index.Fit(documents...); err != nil {
+		t.Fatal(err)
+	}
+	f := NewTfIdfVectorizer(index)
+	vectors := Vectorize(f, documents...)
+	if len(vectors) != len(documents) {
+		t.Fatalf(`len not equals %d, %d`, len(documents), len(vectors))
+	}
+	for i, v := range vectors {
+		if int(v.Id) != i || len(v.V) != f.Dim() {
+			t.Fatalf(`wrong vector %d`, i)
+		}
+		if fmt.Sprintf(`%.4f`, distCos(v.V, v.V)) != `1.0000` {
+			t.Fatalf(`vector %d is not normalized`, i)
+		}
+	}
+
+	iv, err := NewIndexVector(WithMetric(MetricCosine))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = iv.Fit(vectors); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	similar, err := iv.SearchKNN(f.Transform(`build docker image`), 2)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if similar[0].Id != 0 || similar[1].Id != 3 {
+		t.Fatalf(`wrong similar documents %d %d`, similar[0].Id, similar[1].Id)
+	}
+	for _, x := range f.Transform(`unknown words`) {
+		if x != 0 {
+			t.Fatalf(`unknown words must give zero vector`)
+		}
+	}
+
+	golang := f.Transform(`golang`)
+	index.Add(`aaa bbb`)
+	if got := f.Transform(`golang`); fmt.Sprint(got) != fmt.Sprint(golang) || f.Dim() != len(golang) {
+		t.Fatalf(`vectors must not change when index changes, %v != %v`, got, golang)
+	}
+}
+
+func TestHashingVectorizer(t *testing.T) {
+	if _, err := NewHashingVectorizer(0); err != ErrInvalidDimension {
+		t.Fatalf(`expected ErrInvalidDimension`)
+	}
+	f, err := NewHashingVectorizer(64)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	a := f.Transform(`Docker build image`)
+	b := f.Transform(`image build docker`)
+	c := f.Transform(`golang concurrency`)
+	if len(a) != 64 {
+		t.Fatalf(`len not equals 64, %d`, len(a))
+	}
+	if fmt.Sprintf(`%.4f`, distCos(a, b)) != `1.0000` {
+		t.Fatalf(`same words must give same vector`)
+	}
+	if distCos(a, c) > 0.5 {
+		t.Fatalf(`different words must give different vectors`)
+	}
+}
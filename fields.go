@@ -0,0 +1,507 @@
+package word_index
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidField = errors.New(`invalid field`)
+	ErrUnknownField = errors.New(`unknown field`)
+	ErrQuerySyntax  = errors.New(`query syntax error`)
+	ErrInvalidScore = errors.New(`score must be finite`)
+)
+
+const (
+	// fieldSeparator joins field name and term in dictionary of FieldIndex.
+	fieldSeparator = `:`
+	boostSeparator = `^`
+	quote          = `"`
+)
+
+// Analyzer splits field value into indexed terms.
+type Analyzer func(string) []string
+
+// AnalyzerStandard lower cases text and splits it by spaces as MatrixIndex does.
+func AnalyzerStandard(text string) []string {
+	terms := make([]string, 0)
+	for _, term := range strings.Split(strings.ToLower(text), ` `) {
+		if len(term) > 0 {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// FieldType is the type of values of a field.
+type FieldType int
+
+const (
+	FieldText FieldType = iota
+	FieldNumber
+	FieldDate
+	// FieldKeyword is a text field indexed as a single untokenized term with columnar values for facets.
+	FieldKeyword
+)
+
+// Field describes a named field of documents. Indexed fields are searchable,
+// stored fields are returned by FieldIndex.Document. Nil Analyzer means AnalyzerStandard.
+// Values of text and keyword fields are set by Document.SetField, of number and date fields
+// by Document.SetNumber and Document.SetDate.
+type Field struct {
+	Name     string
+	Type     FieldType
+	Analyzer Analyzer
+	Stored   bool
+	Indexed  bool
+}
+
+// Document is a set of named field values. Score is a static score of document,
+// e.g. recency or popularity, added to relevance by FieldIndex.QueryRanked.
+type Document struct {
+	Fields  map[string]string
+	Numbers map[string]float64
+	Score   float64
+}
+
+func NewDocument() *Document {
+	return &Document{Fields: make(map[string]string)}
+}
+
+func (d *Document) SetField(name, value string) *Document {
+	if d.Fields == nil {
+		d.Fields = make(map[string]string)
+	}
+	d.Fields[name] = value
+	return d
+}
+
+func (d *Document) SetNumber(name string, value float64) *Document {
+	if d.Numbers == nil {
+		d.Numbers = make(map[string]float64)
+	}
+	d.Numbers[name] = value
+	return d
+}
+
+// SetDate sets date field to unix time of t in seconds.
+func (d *Document) SetDate(name string, t time.Time) *Document {
+	return d.SetNumber(name, float64(t.Unix()))
+}
+
+// FieldIndex searches documents with named fields. Terms of every field are kept
+// in one MatrixIndex dictionary as field:term, so a field scoped query is a prefix
+// of dictionary terms and uses the same postings as MatrixIndex.
+type FieldIndex struct {
+	fields map[string]*Field
+	// defaults are indexed fields searched by clauses without field
+	defaults []string
+	terms    map[string][]int
+	postings *MatrixIndex
+	numbers  map[string]*numericField
+	columns  map[string]*numberColumn
+	keywords map[string]*keywordColumn
+	stored   []*Document
+	static   []float64
+	// staticWeight is the multiplier of static score in ranked queries
+	staticWeight float64
+}
+
+// Add indexes documents, ids are assigned in order of addition.
+// Nothing is added if any document has a field missing in schema, a value of wrong type or not finite score.
+func (f *FieldIndex) Add(documents ...*Document) error {
+	for _, d := range documents {
+		if err := f.validate(d); err != nil {
+			return err
+		}
+	}
+	for _, d := range documents {
+		id := len(f.stored)
+		stored := &Document{Fields: make(map[string]string), Numbers: make(map[string]float64)}
+		for name, value := range d.Numbers {
+			field := f.fields[name]
+			if field.Stored {
+				stored.Numbers[name] = value
+			}
+			if field.Indexed {
+				f.numbers[name].add(value, id)
+			}
+		}
+		for name, value := range d.Fields {
+			field := f.fields[name]
+			if field.Stored {
+				stored.Fields[name] = value
+			}
+			if field.Indexed {
+				for _, term := range field.analyze(value) {
+					key := name + fieldSeparator + term
+					postings := f.terms[key]
+					if len(postings) == 0 || postings[len(postings)-1] != id {
+						f.terms[key] = append(postings, id)
+					}
+				}
+			}
+		}
+		for name, column := range f.columns {
+			value, ok := d.Numbers[name]
+			column.add(value, ok)
+		}
+		for name, column := range f.keywords {
+			value, ok := d.Fields[name]
+			column.add(value, ok)
+		}
+		f.stored = append(f.stored, stored)
+		f.static = append(f.static, d.Score)
+	}
+	f.rebuild()
+	return nil
+}
+
+func (f *FieldIndex) validate(d *Document) error {
+	if !isFinite(d.Score) {
+		return ErrInvalidScore
+	}
+	for name := range d.Fields {
+		field, ok := f.fields[name]
+		if !ok {
+			return ErrUnknownField
+		}
+		if field.Type != FieldText && field.Type != FieldKeyword {
+			return ErrInvalidField
+		}
+	}
+	for name := range d.Numbers {
+		field, ok := f.fields[name]
+		if !ok {
+			return ErrUnknownField
+		}
+		if field.Type != FieldNumber && field.Type != FieldDate {
+			return ErrInvalidField
+		}
+	}
+	return nil
+}
+
+// rebuild publishes terms as sorted MatrixIndex dictionary, postings are sorted by construction.
+func (f *FieldIndex) rebuild() {
+	items := make([]*matrixIndexItem, 0, len(f.terms))
+	for key, postings := range f.terms {
+		items = append(items, &matrixIndexItem{word: key, index: postings})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].word < items[j].word
+	})
+	f.postings = &MatrixIndex{items: items}
+}
+
+// Len returns number of documents.
+func (f *FieldIndex) Len() int {
+	return len(f.stored)
+}
+
+// Document returns stored fields of document id.
+func (f *FieldIndex) Document(id int) (*Document, bool) {
+	if id < 0 || id >= len(f.stored) {
+		return nil, false
+	}
+	d := NewDocument()
+	d.Score = f.static[id]
+	for name, value := range f.stored[id].Fields {
+		d.SetField(name, value)
+	}
+	for name, value := range f.stored[id].Numbers {
+		d.SetNumber(name, value)
+	}
+	return d, true
+}
+
+// Query returns documents matching any clause of query.
+func (f *FieldIndex) Query(query string) ([]int, error) {
+	return f.QueryAndOr(query, false)
+}
+
+// QueryAndOr returns documents matching any or, if useAnd, every clause of query.
+// Clause is a word as in MatrixIndex optionally scoped to a field: title:docker body:section*,
+// clause without field matches any indexed text field. Range clauses of number and date
+// fields as date:[2024-01-01 TO 2024-12-31] filter documents matched by other clauses.
+// Values with spaces are quoted as category:"Video Courses".
+func (f *FieldIndex) QueryAndOr(query string, useAnd bool) ([]int, error) {
+	clauses, filters, err := f.parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	results := make([][]int, len(clauses))
+	for i, c := range clauses {
+		results[i] = MergeOrderedArray(f.clausePostings(c))
+	}
+	filtered := make([][]int, 0, len(filters)+1)
+	if len(results) > 0 {
+		if useAnd {
+			filtered = append(filtered, MergeOrderedArrayAnd(results))
+		} else {
+			filtered = append(filtered, MergeOrderedArray(results))
+		}
+	}
+	for _, r := range filters {
+		filtered = append(filtered, f.numbers[r.field].search(r))
+	}
+	if len(filtered) == 0 {
+		return []int{}, nil
+	}
+	return MergeOrderedArrayAnd(filtered), nil
+}
+
+// QueryRanked returns documents matching any clause of query ordered by relevance.
+// Relevance is the sum over matched clauses and fields of boost*idf, where boost is
+// set by clause suffix as in title:docker^3, plus static score of document multiplied by static weight.
+// Range clauses filter ranked documents, a query of only range clauses ranks matched documents by static score.
+func (f *FieldIndex) QueryRanked(query string) ([]ScoredDocument, error) {
+	clauses, filters, err := f.parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[int]float64)
+	for _, c := range clauses {
+		for _, name := range c.fields {
+			result := MergeOrderedArray(f.fieldPostings(name, c.word))
+			if len(result) == 0 {
+				continue
+			}
+			score := c.boost * idf(len(f.stored), len(result))
+			for _, id := range result {
+				scores[id] += score
+			}
+		}
+	}
+	if len(filters) > 0 {
+		results := make([][]int, len(filters))
+		for i, r := range filters {
+			results[i] = f.numbers[r.field].search(r)
+		}
+		result := MergeOrderedArrayAnd(results)
+		if len(clauses) == 0 {
+			for _, id := range result {
+				scores[id] = 0
+			}
+		}
+		for id := range scores {
+			if n := sort.SearchInts(result, id); n == len(result) || result[n] != id {
+				delete(scores, id)
+			}
+		}
+	}
+	for id := range scores {
+		scores[id] += f.staticWeight * f.static[id]
+	}
+	return rankScores(scores), nil
+}
+
+// SetStaticWeight sets multiplier of document static score in QueryRanked, 0 disables static scores.
+func (f *FieldIndex) SetStaticWeight(weight float64) error {
+	if !isFinite(weight) {
+		return ErrInvalidScore
+	}
+	f.staticWeight = weight
+	return nil
+}
+
+type fieldClause struct {
+	fields []string
+	word   string
+	boost  float64
+}
+
+// parseQuery returns term clauses and range filters of query.
+func (f *FieldIndex) parseQuery(query string) ([]*fieldClause, []*numericRange, error) {
+	clauses := make([]*fieldClause, 0)
+	filters := make([]*numericRange, 0)
+	words, err := splitQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, w := range words {
+		word, boost, err := parseBoost(w)
+		if err != nil {
+			return nil, nil, err
+		}
+		c := &fieldClause{fields: f.defaults, word: unquote(word), boost: boost}
+		if n := strings.Index(word, fieldSeparator); n > 0 && !strings.HasPrefix(word, quote) {
+			field, ok := f.fields[word[:n]]
+			if !ok || !field.Indexed {
+				return nil, nil, ErrUnknownField
+			}
+			c.fields, c.word = []string{field.Name}, unquote(word[n+1:])
+			if field.Type == FieldNumber || field.Type == FieldDate {
+				if word != w {
+					return nil, nil, ErrQuerySyntax
+				}
+				r, err := parseRange(field, c.word)
+				if err != nil {
+					return nil, nil, err
+				}
+				filters = append(filters, r)
+				continue
+			}
+		}
+		clauses = append(clauses, c)
+	}
+	return clauses, filters, nil
+}
+
+// parseBoost splits word with suffix ^boost into word and boost, boost is 1 without suffix.
+// Boost must be finite and not negative.
+func parseBoost(word string) (string, float64, error) {
+	n := strings.LastIndex(word, boostSeparator)
+	if n < 0 || n < strings.LastIndex(word, quote) {
+		return word, 1, nil
+	}
+	boost, err := strconv.ParseFloat(word[n+1:], 64)
+	if err != nil || n == 0 || boost < 0 || math.IsNaN(boost) || math.IsInf(boost, 0) {
+		return ``, 0, ErrQuerySyntax
+	}
+	return word[:n], boost, nil
+}
+
+// unquote returns value without enclosing double quotes.
+func unquote(value string) string {
+	if len(value) > 1 && strings.HasPrefix(value, quote) && strings.HasSuffix(value, quote) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// splitQuery splits query by spaces except spaces in range brackets and double quotes
+// as in category:"Video Courses".
+func splitQuery(query string) ([]string, error) {
+	words := make([]string, 0)
+	depth, start, quoted := 0, 0, false
+	for n := 0; n <= len(query); n++ {
+		if n < len(query) {
+			if query[n] == '"' {
+				quoted = !quoted
+				continue
+			}
+			if quoted {
+				continue
+			}
+			switch query[n] {
+			case '[', '{':
+				depth++
+				continue
+			case ']', '}':
+				depth--
+				continue
+			case ' ':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if depth != 0 || quoted {
+			return nil, ErrQuerySyntax
+		}
+		if n > start {
+			words = append(words, query[start:n])
+		}
+		start = n + 1
+	}
+	return words, nil
+}
+
+// clausePostings returns postings of all dictionary terms matching clause.
+func (f *FieldIndex) clausePostings(c *fieldClause) [][]int {
+	results := make([][]int, 0)
+	for _, name := range c.fields {
+		results = append(results, f.fieldPostings(name, c.word)...)
+	}
+	return results
+}
+
+// fieldPostings returns postings of terms of field matching word. Words with prefix
+// or variants are lower cased, others are processed by field analyzer. Keyword words match
+// the equal value only, without prefix or variants.
+func (f *FieldIndex) fieldPostings(name, word string) [][]int {
+	results := make([][]int, 0)
+	if f.fields[name].Type == FieldKeyword {
+		if postings, ok := f.terms[name+fieldSeparator+word]; ok {
+			results = append(results, postings)
+		}
+		return results
+	}
+	words := []string{strings.ToLower(word)}
+	if !strings.HasSuffix(word, tagAny) && !strings.HasSuffix(word, `)`) {
+		words = f.fields[name].analyze(word)
+	}
+	for _, w := range words {
+		q, variants := makeVariants(w)
+		// as in MatrixIndex words shorter than 2 bytes, including bare *, match nothing
+		if len(strings.TrimSpace(q)) < 2 {
+			continue
+		}
+		for n := range variants {
+			variants[n] = name + fieldSeparator + variants[n]
+		}
+		results = append(results, f.postings.findTerms(name+fieldSeparator+q, variants)...)
+	}
+	return results
+}
+
+func (field *Field) analyze(text string) []string {
+	if field.Type == FieldKeyword {
+		if len(text) == 0 {
+			return []string{}
+		}
+		return []string{text}
+	}
+	if field.Analyzer == nil {
+		return AnalyzerStandard(text)
+	}
+	return field.Analyzer(text)
+}
+
+// NewFieldIndex returns index of documents with fields, names must be unique and must not contain ':'.
+func NewFieldIndex(fields ...Field) (*FieldIndex, error) {
+	f := &FieldIndex{
+		fields:   make(map[string]*Field),
+		defaults: make([]string, 0),
+		terms:    make(map[string][]int),
+		numbers:  make(map[string]*numericField),
+		columns:  make(map[string]*numberColumn),
+		keywords: make(map[string]*keywordColumn),
+		stored:   make([]*Document, 0),
+		static:   make([]float64, 0),
+
+		staticWeight: 1,
+	}
+	for n := range fields {
+		field := fields[n]
+		if field.Name == `` || strings.Contains(field.Name, fieldSeparator) {
+			return nil, ErrInvalidField
+		}
+		if _, ok := f.fields[field.Name]; ok {
+			return nil, ErrInvalidField
+		}
+		f.fields[field.Name] = &field
+		switch field.Type {
+		case FieldText:
+			if field.Indexed {
+				f.defaults = append(f.defaults, field.Name)
+			}
+		case FieldNumber, FieldDate:
+			f.numbers[field.Name] = &numericField{}
+			f.columns[field.Name] = &numberColumn{}
+		case FieldKeyword:
+			f.keywords[field.Name] = newKeywordColumn()
+		default:
+			return nil, ErrInvalidField
+		}
+	}
+	f.rebuild()
+	return f, nil
+}
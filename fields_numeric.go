@@ -0,0 +1,153 @@
+package word_index
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dateLayouts are accepted formats of date values in queries, the first one is a date without time.
+var dateLayouts = []string{`2006-01-02`, time.RFC3339}
+
+type numericPosting struct {
+	value float64
+	id    int
+}
+
+// numericField keeps values of number or date field sorted by value,
+// a range is found by binary search. Postings are sorted once by the first search after add.
+type numericField struct {
+	mx       sync.Mutex
+	postings []numericPosting
+	sorted   bool
+}
+
+func (n *numericField) add(value float64, id int) {
+	n.postings = append(n.postings, numericPosting{value: value, id: id})
+	n.sorted = false
+}
+
+func (n *numericField) sort() {
+	n.mx.Lock()
+	defer n.mx.Unlock()
+	if n.sorted {
+		return
+	}
+	sort.SliceStable(n.postings, func(i, j int) bool {
+		return n.postings[i].value < n.postings[j].value
+	})
+	n.sorted = true
+}
+
+// search returns sorted ids of documents with value in range r.
+func (n *numericField) search(r *numericRange) []int {
+	n.sort()
+	low := sort.Search(len(n.postings), func(i int) bool {
+		if r.lowInclusive {
+			return n.postings[i].value >= r.low
+		}
+		return n.postings[i].value > r.low
+	})
+	result := make([]int, 0)
+	for i := low; i < len(n.postings); i++ {
+		v := n.postings[i].value
+		if v > r.high || v == r.high && !r.highInclusive {
+			break
+		}
+		result = append(result, n.postings[i].id)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// numberColumn keeps value of number or date field of every document for sorting.
+type numberColumn struct {
+	values []float64
+	has    []bool
+}
+
+func (c *numberColumn) add(value float64, ok bool) {
+	c.values = append(c.values, value)
+	c.has = append(c.has, ok)
+}
+
+// numericRange is a range clause, open bounds are infinite.
+type numericRange struct {
+	field         string
+	low           float64
+	high          float64
+	lowInclusive  bool
+	highInclusive bool
+}
+
+// parseRange parses [low TO high] with inclusive and {low TO high} with exclusive bounds,
+// * is an open bound. A single value matches equal values. A date without time is the whole day,
+// so date:2024-12-31 and an inclusive upper bound 2024-12-31 match any time of that day.
+func parseRange(field *Field, value string) (*numericRange, error) {
+	r := &numericRange{field: field.Name, lowInclusive: true, highInclusive: true}
+	if len(value) < 2 || !strings.ContainsAny(value[:1], `[{`) {
+		low, high, err := parseNumeric(field, value)
+		if err != nil {
+			return nil, err
+		}
+		r.low, r.high = low, high
+		return r, nil
+	}
+
+	last := value[len(value)-1]
+	if last != ']' && last != '}' {
+		return nil, ErrQuerySyntax
+	}
+	r.lowInclusive, r.highInclusive = value[0] == '[', last == ']'
+	bounds := strings.Fields(value[1 : len(value)-1])
+	if len(bounds) != 3 || bounds[1] != `TO` {
+		return nil, ErrQuerySyntax
+	}
+	r.low, r.high = math.Inf(-1), math.Inf(1)
+	if bounds[0] != tagAny {
+		low, high, err := parseNumeric(field, bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		// an exclusive bound excludes the whole day
+		r.low = low
+		if !r.lowInclusive {
+			r.low = high
+		}
+	}
+	if bounds[2] != tagAny {
+		low, high, err := parseNumeric(field, bounds[2])
+		if err != nil {
+			return nil, err
+		}
+		r.high = low
+		if r.highInclusive {
+			r.high = high
+		}
+	}
+	return r, nil
+}
+
+// parseNumeric parses number or, for date field, date as unix time in seconds.
+// It returns the first and the last value denoted by value, they differ only for a date without time.
+func parseNumeric(field *Field, value string) (float64, float64, error) {
+	if field.Type == FieldDate {
+		if t, err := time.Parse(dateLayouts[0], value); err == nil {
+			return float64(t.Unix()), float64(t.AddDate(0, 0, 1).Unix() - 1), nil
+		}
+		for _, layout := range dateLayouts[1:] {
+			if t, err := time.Parse(layout, value); err == nil {
+				return float64(t.Unix()), float64(t.Unix()), nil
+			}
+		}
+		return 0, 0, ErrQuerySyntax
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, 0, ErrQuerySyntax
+	}
+	return v, v, nil
+}
@@ -0,0 +1,123 @@
+package word_index
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFieldIndex_Range(t *testing.T) {
+	index, err := NewFieldIndex(
+		Field{Name: `title`, Indexed: true, Stored: true},
+		Field{Name: `price`, Type: FieldNumber, Indexed: true, Stored: true},
+		Field{Name: `date`, Type: FieldDate, Indexed: true},
+	)
+	if err != nil {
+		t.Fatalf(`error create field index: %v`, err)
+	}
+	day := func(s string) time.Time {
+		d, _ := time.Parse(`2006-01-02`, s)
+		return d
+	}
+	index.Add(
+		NewDocument().SetField(`title`, `docker book`).SetNumber(`price`, 10).SetDate(`date`, day(`2023-05-01`)),
+		NewDocument().SetField(`title`, `docker course`).SetNumber(`price`, 25).SetDate(`date`, day(`2024-01-01`)),
+		NewDocument().SetField(`title`, `helm book`).SetNumber(`price`, 40).SetDate(`date`, day(`2024-12-31`)),
+		NewDocument().SetField(`title`, `docker talk`),
+	)
+
+	tests := []struct {
+		query  string
+		result []int
+	}{
+		{`date:[2024-01-01 TO 2024-12-31]`, []int{1, 2}},
+		{`docker date:[2024-01-01 TO 2024-12-31]`, []int{1}},
+		{`docker date:{2024-01-01 TO *]`, []int{}},
+		{`book price:[* TO 40}`, []int{0}},
+		{`docker helm price:[20 TO 50]`, []int{1, 2}},
+		{`price:25`, []int{1}},
+		{`price:[10 TO 40] date:[* TO 2024-06-01]`, []int{0, 1}},
+		{`docker`, []int{0, 1, 3}},
+	}
+	for _, test := range tests {
+		result, err := index.Query(test.query)
+		if err != nil {
+			t.Fatalf(`error query %s: %v`, test.query, err)
+		}
+		if !reflect.DeepEqual(result, test.result) {
+			t.Fatalf(`query %s: %v not equals %v`, test.query, result, test.result)
+		}
+	}
+
+	ranked, err := index.QueryRanked(`docker^2 book price:[* TO 20]`)
+	if err != nil {
+		t.Fatalf(`error ranked query: %v`, err)
+	}
+	if len(ranked) != 1 || ranked[0].Id != 0 {
+		t.Fatalf(`wrong ranked range result %v`, ranked)
+	}
+
+	ranked, err = index.QueryRanked(`price:[* TO 30]`)
+	if err != nil {
+		t.Fatalf(`error ranked query: %v`, err)
+	}
+	if len(ranked) != 2 || ranked[0].Id != 0 || ranked[1].Id != 1 {
+		t.Fatalf(`range only ranked query must return range result, %v`, ranked)
+	}
+	cheap := NewDocument().SetField(`title`, `helm talk`).SetNumber(`price`, 5)
+	cheap.Score = 2
+	if err = index.Add(cheap); err != nil {
+		t.Fatalf(`error add: %v`, err)
+	}
+	ranked, _ = index.QueryRanked(`price:[* TO 30]`)
+	if len(ranked) != 3 || ranked[0].Id != 4 || ranked[0].Score != 2 {
+		t.Fatalf(`range only ranked query must be ordered by static score, %v`, ranked)
+	}
+
+	for _, query := range []string{`price:[1 TO`, `price:[1 2]`, `price:abc`, `date:[2024 TO *]`, `price:[1 TO 5]^2`, `price:25^1`} {
+		if _, err := index.Query(query); err != ErrQuerySyntax {
+			t.Fatalf(`query %s: expected ErrQuerySyntax, %v`, query, err)
+		}
+	}
+
+	if d, _ := index.Document(2); d.Numbers[`price`] != 40 {
+		t.Fatalf(`stored price not equals 40, %v`, d.Numbers)
+	}
+	if err := index.Add(NewDocument().SetField(`price`, `10`)); err != ErrInvalidField {
+		t.Fatalf(`expected ErrInvalidField, %v`, err)
+	}
+}
+
+func TestFieldIndex_RangeDay(t *testing.T) {
+	index, err := NewFieldIndex(Field{Name: `date`, Type: FieldDate, Indexed: true})
+	if err != nil {
+		t.Fatalf(`error create field index: %v`, err)
+	}
+	index.Add(
+		NewDocument().SetDate(`date`, time.Date(2024, 12, 31, 15, 0, 0, 0, time.UTC)),
+		NewDocument().SetDate(`date`, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		NewDocument().SetDate(`date`, time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)),
+	)
+
+	tests := []struct {
+		query  string
+		result []int
+	}{
+		{`date:[2024-01-01 TO 2024-12-31]`, []int{0, 2}},
+		{`date:2024-12-31`, []int{0}},
+		{`date:[2024-01-01 TO 2024-12-31}`, []int{2}},
+		{`date:{2024-01-01 TO *]`, []int{0, 1}},
+		{`date:[* TO 2025-01-01]`, []int{0, 1, 2}},
+		{`date:2024-12-31T15:00:00Z`, []int{0}},
+		{`date:[2024-12-31T15:00:01Z TO *]`, []int{1}},
+	}
+	for _, test := range tests {
+		result, err := index.Query(test.query)
+		if err != nil {
+			t.Fatalf(`error query %s: %v`, test.query, err)
+		}
+		if !reflect.DeepEqual(result, test.result) {
+			t.Fatalf(`query %s: %v not equals %v`, test.query, result, test.result)
+		}
+	}
+}
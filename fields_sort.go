@@ -0,0 +1,167 @@
+package word_index
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+)
+
+// SortMissing is the position of documents without value of a sort key.
+type SortMissing int
+
+const (
+	// SortMissingLast places documents without value after others in any direction.
+	SortMissingLast SortMissing = iota
+	SortMissingFirst
+)
+
+// SortField is a sort key by number, date or keyword field of FieldIndex.
+type SortField struct {
+	Field   string
+	Desc    bool
+	Missing SortMissing
+}
+
+// FieldSearchOptions selects a page of FieldIndex hits. Limit 0 means all hits after Offset,
+// empty Sort means document order.
+type FieldSearchOptions struct {
+	Offset int
+	Limit  int
+	Sort   []SortField
+}
+
+// docComparator orders documents by sort keys, ties are ordered by id.
+type docComparator struct {
+	keys     []SortField
+	numbers  []*numberColumn
+	keywords []*keywordColumn
+}
+
+// less reports whether document a is ordered before document b.
+func (c *docComparator) less(a, b int) bool {
+	for n, key := range c.keys {
+		var hasA, hasB bool
+		cmp := 0
+		if column := c.numbers[n]; column != nil {
+			hasA, hasB = column.has[a], column.has[b]
+			if va, vb := column.values[a], column.values[b]; va < vb {
+				cmp = -1
+			} else if va > vb {
+				cmp = 1
+			}
+		} else {
+			column := c.keywords[n]
+			hasA, hasB = column.ords[a] != emptyFind, column.ords[b] != emptyFind
+			if hasA && hasB {
+				cmp = strings.Compare(column.values[column.ords[a]], column.values[column.ords[b]])
+			}
+		}
+
+		if hasA != hasB {
+			return hasA == (key.Missing == SortMissingLast)
+		}
+		if !hasA || cmp == 0 {
+			continue
+		}
+		if key.Desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	return a < b
+}
+
+// docHeap is a max-heap by comparator, the root is the last of kept documents.
+type docHeap struct {
+	ids []int
+	c   *docComparator
+}
+
+func (h *docHeap) Len() int           { return len(h.ids) }
+func (h *docHeap) Less(i, j int) bool { return h.c.less(h.ids[j], h.ids[i]) }
+func (h *docHeap) Swap(i, j int)      { h.ids[i], h.ids[j] = h.ids[j], h.ids[i] }
+func (h *docHeap) Push(x interface{}) { h.ids = append(h.ids, x.(int)) }
+func (h *docHeap) Pop() interface{} {
+	x := h.ids[len(h.ids)-1]
+	h.ids = h.ids[:len(h.ids)-1]
+	return x
+}
+
+// push keeps at most k first documents.
+func (h *docHeap) push(id, k int) {
+	if h.Len() < k {
+		heap.Push(h, id)
+	} else if h.c.less(id, h.ids[0]) {
+		h.ids[0] = id
+		heap.Fix(h, 0)
+	}
+}
+
+func (f *FieldIndex) comparator(keys []SortField) (*docComparator, error) {
+	c := &docComparator{
+		keys:     keys,
+		numbers:  make([]*numberColumn, len(keys)),
+		keywords: make([]*keywordColumn, len(keys)),
+	}
+	for n, key := range keys {
+		field, ok := f.fields[key.Field]
+		if !ok {
+			return nil, ErrUnknownField
+		}
+		if key.Missing != SortMissingLast && key.Missing != SortMissingFirst {
+			return nil, ErrInvalidField
+		}
+		switch field.Type {
+		case FieldNumber, FieldDate:
+			c.numbers[n] = f.columns[key.Field]
+		case FieldKeyword:
+			c.keywords[n] = f.keywords[key.Field]
+		default:
+			return nil, ErrInvalidField
+		}
+	}
+	return c, nil
+}
+
+// Search returns a page of documents matching query as in Query with exact total.
+// Hits are ordered by opts.Sort keys, only the first Offset+Limit hits are
+// kept in a heap while matches are scanned.
+func (f *FieldIndex) Search(query string, opts FieldSearchOptions) (SearchResult, error) {
+	result, err := f.Query(query)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	page := SearchResult{Hits: result, Total: len(result), TotalExact: true}
+
+	if len(opts.Sort) > 0 {
+		c, err := f.comparator(opts.Sort)
+		if err != nil {
+			return SearchResult{}, err
+		}
+		if k := opts.Offset + opts.Limit; opts.Limit > 0 && k < len(result) {
+			h := &docHeap{ids: make([]int, 0, k), c: c}
+			for _, id := range result {
+				h.push(id, k)
+			}
+			page.Hits = h.ids
+		} else {
+			page.Hits = append([]int(nil), result...)
+		}
+		sort.Slice(page.Hits, func(i, j int) bool {
+			return c.less(page.Hits[i], page.Hits[j])
+		})
+	}
+
+	if opts.Offset >= len(page.Hits) {
+		page.Hits = []int{}
+		return page, nil
+	}
+	page.Hits = page.Hits[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(page.Hits) {
+		page.Hits = page.Hits[:opts.Limit]
+	}
+	return page, nil
+}
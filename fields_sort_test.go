@@ -0,0 +1,64 @@
+package word_index
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldIndex_SearchSort(t *testing.T) {
+	index, err := NewFieldIndex(
+		Field{Name: `title`, Indexed: true},
+		Field{Name: `category`, Type: FieldKeyword, Indexed: true},
+		Field{Name: `price`, Type: FieldNumber, Indexed: true},
+	)
+	if err != nil {
+		t.Fatalf(`error create field index: %v`, err)
+	}
+	index.Add(
+		NewDocument().SetField(`title`, `docker a`).SetField(`category`, `b`).SetNumber(`price`, 30),
+		NewDocument().SetField(`title`, `docker b`).SetField(`category`, `a`).SetNumber(`price`, 10),
+		NewDocument().SetField(`title`, `docker c`).SetField(`category`, `b`).SetNumber(`price`, 20),
+		NewDocument().SetField(`title`, `docker d`).SetField(`category`, `a`),
+		NewDocument().SetField(`title`, `docker e`).SetNumber(`price`, 10),
+		NewDocument().SetField(`title`, `helm`).SetNumber(`price`, 1),
+	)
+
+	tests := []struct {
+		sort   []SortField
+		offset int
+		limit  int
+		hits   []int
+	}{
+		{nil, 1, 2, []int{1, 2}},
+		{[]SortField{{Field: `price`}}, 0, 0, []int{1, 4, 2, 0, 3}},
+		{[]SortField{{Field: `price`, Desc: true}}, 0, 3, []int{0, 2, 1}},
+		{[]SortField{{Field: `price`, Missing: SortMissingFirst}}, 0, 2, []int{3, 1}},
+		{[]SortField{{Field: `price`, Desc: true, Missing: SortMissingFirst}}, 0, 0, []int{3, 0, 2, 1, 4}},
+		{[]SortField{{Field: `category`}, {Field: `price`, Desc: true}}, 0, 0, []int{1, 3, 0, 2, 4}},
+		{[]SortField{{Field: `category`, Desc: true}, {Field: `price`}}, 1, 3, []int{0, 1, 3}},
+		{[]SortField{{Field: `price`}}, 4, 10, []int{3}},
+		{[]SortField{{Field: `price`}}, 10, 10, []int{}},
+	}
+	for i, test := range tests {
+		result, err := index.Search(`docker`, FieldSearchOptions{Offset: test.offset, Limit: test.limit, Sort: test.sort})
+		if err != nil {
+			t.Fatalf(`%d: error search: %v`, i, err)
+		}
+		if result.Total != 5 || !result.TotalExact {
+			t.Fatalf(`%d: total not equals 5, %d`, i, result.Total)
+		}
+		if !reflect.DeepEqual(result.Hits, test.hits) {
+			t.Fatalf(`%d: hits %v not equals %v`, i, result.Hits, test.hits)
+		}
+	}
+
+	if _, err := index.Search(`docker`, FieldSearchOptions{Sort: []SortField{{Field: `title`}}}); err != ErrInvalidField {
+		t.Fatalf(`expected ErrInvalidField, %v`, err)
+	}
+	if _, err := index.Search(`docker`, FieldSearchOptions{Sort: []SortField{{Field: `price`, Missing: SortMissingFirst + 1}}}); err != ErrInvalidField {
+		t.Fatalf(`expected ErrInvalidField, %v`, err)
+	}
+	if _, err := index.Search(`docker`, FieldSearchOptions{Sort: []SortField{{Field: `brand`}}}); err != ErrUnknownField {
+		t.Fatalf(`expected ErrUnknownField, %v`, err)
+	}
+}
@@ -0,0 +1,158 @@
+package word_index
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestFieldIndex(t *testing.T) *FieldIndex {
+	index, err := NewFieldIndex(
+		Field{Name: `title`, Stored: true, Indexed: true},
+		Field{Name: `body`, Indexed: true},
+		Field{Name: `tags`, Stored: true, Indexed: true, Analyzer: func(text string) []string {
+			return strings.Split(strings.ToLower(text), `,`)
+		}},
+	)
+	if err != nil {
+		t.Fatalf(`error create field index: %v`, err)
+	}
+	err = index.Add(
+		NewDocument().SetField(`title`, `Docker basics`).SetField(`body`, `first section about images`).SetField(`tags`, `Containers,Ops`),
+		NewDocument().SetField(`title`, `Kubernetes`).SetField(`body`, `docker sections and pods`).SetField(`tags`, `ops`),
+		NewDocument().SetField(`title`, `Helm charts`).SetField(`body`, `sectioned docs`),
+	)
+	if err != nil {
+		t.Fatalf(`error add documents: %v`, err)
+	}
+	return index
+}
+
+func TestFieldIndex_Query(t *testing.T) {
+	index := newTestFieldIndex(t)
+	tests := []struct {
+		query  string
+		useAnd bool
+		result []int
+	}{
+		{`title:docker`, false, []int{0}},
+		{`docker`, false, []int{0, 1}},
+		{`body:section*`, false, []int{0, 1, 2}},
+		{`body:section(s|ed)`, false, []int{0, 1, 2}},
+		{`body:sections`, false, []int{1}},
+		{`title:docker body:pods`, false, []int{0, 1}},
+		{`title:docker body:section*`, true, []int{0}},
+		{`tags:ops`, false, []int{0, 1}},
+		{`tags:containers tags:ops`, true, []int{0}},
+		{`title:pods`, false, []int{}},
+		{`*`, false, []int{}},
+		{`title:*`, false, []int{}},
+		{`title:d*`, false, []int{0}},
+	}
+	for _, test := range tests {
+		result, err := index.QueryAndOr(test.query, test.useAnd)
+		if err != nil {
+			t.Fatalf(`error query %s: %v`, test.query, err)
+		}
+		if !reflect.DeepEqual(result, test.result) {
+			t.Fatalf(`query %s: %v not equals %v`, test.query, result, test.result)
+		}
+	}
+
+	if _, err := index.Query(`author:bob`); err != ErrUnknownField {
+		t.Fatalf(`expected ErrUnknownField, %v`, err)
+	}
+
+	// one letter words match nothing as in MatrixIndex
+	index.Add(NewDocument().SetField(`title`, `a b docker`))
+	if result, _ := index.Query(`a`); len(result) != 0 {
+		t.Fatalf(`one letter word must match nothing, %v`, result)
+	}
+	if result, _ := index.Query(`title:b`); len(result) != 0 {
+		t.Fatalf(`one letter word must match nothing, %v`, result)
+	}
+}
+
+func TestFieldIndex_Document(t *testing.T) {
+	index := newTestFieldIndex(t)
+	d, ok := index.Document(1)
+	if !ok {
+		t.Fatalf(`document not found`)
+	}
+	if d.Fields[`title`] != `Kubernetes` || d.Fields[`tags`] != `ops` {
+		t.Fatalf(`wrong stored fields %v`, d.Fields)
+	}
+	if _, ok := d.Fields[`body`]; ok {
+		t.Fatalf(`body must not be stored`)
+	}
+	if _, ok := index.Document(3); ok {
+		t.Fatalf(`document must not exist`)
+	}
+
+	if err := index.Add(NewDocument().SetField(`author`, `bob`)); err != ErrUnknownField {
+		t.Fatalf(`expected ErrUnknownField, %v`, err)
+	}
+	if index.Len() != 3 {
+		t.Fatalf(`documents not equals 3, %d`, index.Len())
+	}
+	if _, err := NewFieldIndex(Field{Name: `a:b`}); err != ErrInvalidField {
+		t.Fatalf(`expected ErrInvalidField, %v`, err)
+	}
+	if _, err := NewFieldIndex(Field{Name: `a`, Type: FieldKeyword + 1}); err != ErrInvalidField {
+		t.Fatalf(`expected ErrInvalidField, %v`, err)
+	}
+}
+
+func TestFieldIndex_QueryRanked(t *testing.T) {
+	index, _ := NewFieldIndex(
+		Field{Name: `title`, Indexed: true},
+		Field{Name: `body`, Indexed: true},
+	)
+	index.Add(
+		NewDocument().SetField(`title`, `intro`).SetField(`body`, `docker docker`),
+		NewDocument().SetField(`title`, `docker`).SetField(`body`, `intro`),
+		&Document{Fields: map[string]string{`title`: `news`, `body`: `docker`}, Score: 10},
+		NewDocument().SetField(`title`, `other`),
+	)
+
+	if err := index.SetStaticWeight(0); err != nil {
+		t.Fatalf(`error set static weight: %v`, err)
+	}
+	ranked, err := index.QueryRanked(`title:docker^3 body:docker`)
+	if err != nil {
+		t.Fatalf(`error query: %v`, err)
+	}
+	if len(ranked) != 3 || ranked[0].Id != 1 {
+		t.Fatalf(`title match must be first %v`, ranked)
+	}
+	if ranked[1].Score != ranked[2].Score {
+		t.Fatalf(`body matches must have equal scores %v`, ranked)
+	}
+
+	if err = index.SetStaticWeight(math.NaN()); err != ErrInvalidScore {
+		t.Fatalf(`expected ErrInvalidScore, %v`, err)
+	}
+	if err = index.Add(&Document{Fields: map[string]string{`title`: `docker`}, Score: math.NaN()}); err != ErrInvalidScore {
+		t.Fatalf(`expected ErrInvalidScore, %v`, err)
+	}
+	if err = index.Add(&Document{Fields: map[string]string{`title`: `docker`}, Score: math.Inf(1)}); err != ErrInvalidScore {
+		t.Fatalf(`expected ErrInvalidScore, %v`, err)
+	}
+	index.SetStaticWeight(1)
+	ranked, _ = index.QueryRanked(`title:docker^3 body:docker`)
+	if ranked[0].Id != 2 || ranked[0].Score <= ranked[1].Score {
+		t.Fatalf(`static score must rank document 2 first %v`, ranked)
+	}
+
+	ranked, _ = index.QueryRanked(`docker`)
+	if len(ranked) != 3 {
+		t.Fatalf(`clause without field must match all fields %v`, ranked)
+	}
+
+	for _, query := range []string{`title:docker^x`, `^2`, `docker^-1`, `docker^NaN`, `docker^Inf`, `docker^-Inf`} {
+		if _, err := index.QueryRanked(query); err != ErrQuerySyntax {
+			t.Fatalf(`query %s: expected ErrQuerySyntax, %v`, query, err)
+		}
+	}
+}
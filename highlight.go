@@ -0,0 +1,170 @@
+package word_index
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrDocumentNotFound = errors.New(`document not found`)
+	ErrInvalidSnippet   = errors.New(`invalid snippet parameters`)
+)
+
+// Span is a matched word of document at byte offsets [Start, End),
+// Term is the query word it matched.
+type Span struct {
+	Start int
+	End   int
+	Term  string
+}
+
+// Highlighter finds words of documents matched by query exactly, by variant or by prefix.
+type Highlighter struct {
+	words int
+	pre   string
+	post  string
+}
+
+type HighlighterOption func(*Highlighter) error
+
+// WithSnippetWords sets number of words in snippet window.
+func WithSnippetWords(n int) HighlighterOption {
+	return func(h *Highlighter) error {
+		if n <= 0 {
+			return ErrInvalidSnippet
+		}
+		h.words = n
+		return nil
+	}
+}
+
+// WithMarkers sets strings inserted before and after matched words in snippet.
+func WithMarkers(pre, post string) HighlighterOption {
+	return func(h *Highlighter) error {
+		h.pre, h.post = pre, post
+		return nil
+	}
+}
+
+// Highlight returns spans of all words of document id matched by query.
+// Query words shorter than 2 bytes are skipped as MatrixIndex never matches them.
+func (h *Highlighter) Highlight(index Index, query string, id int) ([]Span, error) {
+	if id < 0 {
+		return nil, ErrDocumentNotFound
+	}
+	document, ok := index.DocumentAt(id)
+	if !ok {
+		return nil, ErrDocumentNotFound
+	}
+	return highlightDocument(document, query), nil
+}
+
+// Snippet returns the window of document id with most distinct matched query words,
+// ties are resolved by number of matches then by position, matches are wrapped in markers.
+func (h *Highlighter) Snippet(index Index, query string, id int) (string, error) {
+	if id < 0 {
+		return ``, ErrDocumentNotFound
+	}
+	document, ok := index.DocumentAt(id)
+	if !ok {
+		return ``, ErrDocumentNotFound
+	}
+	words := documentWords(document)
+	if len(words) == 0 {
+		return ``, nil
+	}
+	spans := highlightDocument(document, query)
+
+	// matched[n] is the query word matched by document word n
+	matched := make([]string, len(words))
+	for s, n := 0, 0; s < len(spans); n++ {
+		if words[n].Start == spans[s].Start {
+			matched[n] = spans[s].Term
+			s++
+		}
+	}
+
+	size := h.words
+	if size > len(words) {
+		size = len(words)
+	}
+	best, bestTerms, bestMatches := 0, -1, -1
+	for start := 0; start+size <= len(words); start++ {
+		terms := make(map[string]struct{})
+		matches := 0
+		for _, term := range matched[start : start+size] {
+			if term != `` {
+				terms[term] = struct{}{}
+				matches++
+			}
+		}
+		if len(terms) > bestTerms || len(terms) == bestTerms && matches > bestMatches {
+			best, bestTerms, bestMatches = start, len(terms), matches
+		}
+	}
+
+	var b strings.Builder
+	last := words[best].Start
+	for n := best; n < best+size; n++ {
+		if matched[n] == `` {
+			continue
+		}
+		b.WriteString(document[last:words[n].Start])
+		b.WriteString(h.pre)
+		b.WriteString(document[words[n].Start:words[n].End])
+		b.WriteString(h.post)
+		last = words[n].End
+	}
+	b.WriteString(document[last:words[best+size-1].End])
+	return b.String(), nil
+}
+
+func highlightDocument(document, query string) []Span {
+	terms := make([]*variant, 0)
+	for _, word := range strings.Split(strings.ToLower(query), ` `) {
+		if len(word) < 2 {
+			continue
+		}
+		q, v := makeVariants(word)
+		terms = append(terms, &variant{query: q, variants: v})
+	}
+
+	spans := make([]Span, 0)
+	for _, span := range documentWords(document) {
+		word := strings.ToLower(document[span.Start:span.End])
+		for _, term := range terms {
+			if matchWord(word, term.query, term.variants) {
+				span.Term = term.query
+				spans = append(spans, span)
+				break
+			}
+		}
+	}
+	return spans
+}
+
+// documentWords returns spans of space separated words as they are indexed.
+func documentWords(document string) []Span {
+	spans := make([]Span, 0)
+	start := 0
+	for n := 0; n <= len(document); n++ {
+		if n == len(document) || document[n] == ' ' {
+			if n > start {
+				spans = append(spans, Span{Start: start, End: n})
+			}
+			start = n + 1
+		}
+	}
+	return spans
+}
+
+// NewHighlighter returns highlighter with 10 words snippets and <b></b> markers by default.
+func NewHighlighter(opts ...HighlighterOption) (*Highlighter, error) {
+	h := &Highlighter{words: 10, pre: `<b>`, post: `</b>`}
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
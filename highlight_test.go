@@ -0,0 +1,89 @@
+package word_index
+
+import "testing"
+
+func TestHighlighter(t *testing.T) {
+	index := NewMatrixIndex()
+	index.Fit(
+		`Docker images are built by docker build`,
+		`one two three four five six seven eight nine ten eleven twelve kubernetes pods run dockers`,
+	)
+	h, err := NewHighlighter(WithSnippetWords(4), WithMarkers(`[`, `]`))
+	if err != nil {
+		t.Fatalf(`error create highlighter: %v`, err)
+	}
+
+	spans, err := h.Highlight(index, `docker buil*`, 0)
+	if err != nil {
+		t.Fatalf(`error highlight: %v`, err)
+	}
+	if len(spans) != 4 {
+		t.Fatalf(`spans not equals 4, %v`, spans)
+	}
+	if spans[0].Start != 0 || spans[0].End != 6 || spans[0].Term != `docker` {
+		t.Fatalf(`wrong first span %v`, spans[0])
+	}
+	if spans[1].Start != 18 || spans[1].End != 23 || spans[1].Term != `buil*` {
+		t.Fatalf(`wrong prefix span %v`, spans[1])
+	}
+
+	spans, _ = h.Highlight(index, `docker(s|ed)`, 1)
+	if len(spans) != 1 || spans[0].Term != `docker(s|ed)` {
+		t.Fatalf(`wrong variant span %v`, spans)
+	}
+
+	snippet, err := h.Snippet(index, `kubernetes pods three`, 1)
+	if err != nil {
+		t.Fatalf(`error snippet: %v`, err)
+	}
+	if snippet != `eleven twelve [kubernetes] [pods]` {
+		t.Fatalf(`wrong snippet %s`, snippet)
+	}
+
+	snippet, _ = h.Snippet(index, `unknown`, 0)
+	if snippet != `Docker images are built` {
+		t.Fatalf(`wrong snippet without matches %s`, snippet)
+	}
+
+	if _, err := h.Highlight(index, `docker`, 5); err != ErrDocumentNotFound {
+		t.Fatalf(`expected ErrDocumentNotFound, %v`, err)
+	}
+	if _, err := h.Highlight(index, `docker`, -1); err != ErrDocumentNotFound {
+		t.Fatalf(`expected ErrDocumentNotFound for negative id, %v`, err)
+	}
+	if _, err := h.Snippet(index, `docker`, -1); err != ErrDocumentNotFound {
+		t.Fatalf(`expected ErrDocumentNotFound for negative id, %v`, err)
+	}
+	unchecked := sliceIndex{documents: []string{`docker`}}
+	if _, err := h.Highlight(unchecked, `docker`, -1); err != ErrDocumentNotFound {
+		t.Fatalf(`expected ErrDocumentNotFound for negative id, %v`, err)
+	}
+	if _, err := h.Snippet(unchecked, `docker`, -1); err != ErrDocumentNotFound {
+		t.Fatalf(`expected ErrDocumentNotFound for negative id, %v`, err)
+	}
+
+	short := NewMatrixIndex()
+	short.Fit(`a docker b`)
+	if spans, _ := h.Highlight(short, `a b docker`, 0); len(spans) != 1 || spans[0].Term != `docker` {
+		t.Fatalf(`one letter words must not be highlighted %v`, spans)
+	}
+	if ids := short.Query(`a b`); len(ids) != 0 {
+		t.Fatalf(`one letter words must not match %v`, ids)
+	}
+	if _, err := NewHighlighter(WithSnippetWords(0)); err != ErrInvalidSnippet {
+		t.Fatalf(`expected ErrInvalidSnippet, %v`, err)
+	}
+}
+
+// sliceIndex returns documents without checking negative ids.
+type sliceIndex struct {
+	Index
+	documents []string
+}
+
+func (s sliceIndex) DocumentAt(id int) (string, bool) {
+	if id >= len(s.documents) {
+		return ``, false
+	}
+	return s.documents[id], true
+}
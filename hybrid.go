@@ -0,0 +1,201 @@
+package word_index
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var (
+	ErrInvalidFusion = errors.New(`invalid fusion parameters`)
+)
+
+// fusion is the method combining text and vector results, it is selected by WithRRF and WithWeightedFusion.
+type fusion int
+
+const (
+	fusionRRF fusion = iota
+	fusionWeighted
+)
+
+// HybridResult is a document found by HybridSearcher. Document id of MatrixIndex
+// is the Vector.Id of the same document in the vector index.
+type HybridResult struct {
+	Id          int
+	Score       float64
+	TextRank    int
+	VectorRank  int
+	TextScore   float64
+	VectorScore float64
+}
+
+// HybridSearcher combines keyword hits of MatrixIndex with vector similarity.
+type HybridSearcher struct {
+	text       *MatrixIndex
+	vectors    VectorIndex
+	metric     Metric
+	fusion     fusion
+	rrfK       float64
+	textWeight float64
+	candidates int
+}
+
+type HybridOption func(*HybridSearcher) error
+
+// WithRRF fuses results by Reciprocal Rank Fusion: sum of 1/(k+rank) over both lists.
+func WithRRF(k int) HybridOption {
+	return func(h *HybridSearcher) error {
+		if k < 0 {
+			return ErrInvalidFusion
+		}
+		h.fusion, h.rrfK = fusionRRF, float64(k)
+		return nil
+	}
+}
+
+// WithWeightedFusion blends min-max normalized scores: textWeight*text + (1-textWeight)*vector.
+// Vector score is 1/(1+distance) by metric, nil metric means Euclidean.
+func WithWeightedFusion(textWeight float64, metric Metric) HybridOption {
+	return func(h *HybridSearcher) error {
+		if textWeight < 0 || textWeight > 1 {
+			return ErrInvalidFusion
+		}
+		if metric == nil {
+			metric = MetricEuclidean
+		}
+		h.fusion, h.textWeight, h.metric = fusionWeighted, textWeight, metric
+		return nil
+	}
+}
+
+// WithCandidates sets number of hits taken from each index before fusion.
+func WithCandidates(n int) HybridOption {
+	return func(h *HybridSearcher) error {
+		if n <= 0 {
+			return ErrInvalidFusion
+		}
+		h.candidates = n
+		return nil
+	}
+}
+
+// Search returns top k documents for text query and query vector, any of them may be empty.
+func (h *HybridSearcher) Search(query string, v []float64, k int) ([]HybridResult, error) {
+	if k <= 0 {
+		return []HybridResult{}, nil
+	}
+	candidates := h.candidates
+	if candidates < k {
+		candidates = k
+	}
+
+	results := make(map[int]*HybridResult)
+	get := func(id int) *HybridResult {
+		r, ok := results[id]
+		if !ok {
+			r = &HybridResult{Id: id}
+			results[id] = r
+		}
+		return r
+	}
+
+	if query != `` && h.text != nil {
+		hits := h.text.QueryRanked(query)
+		if len(hits) > candidates {
+			hits = hits[:candidates]
+		}
+		for i, hit := range hits {
+			r := get(hit.Id)
+			r.TextRank, r.TextScore = i+1, hit.Score
+		}
+	}
+	if len(v) > 0 && h.vectors != nil {
+		hits, err := h.vectors.SearchKNN(v, candidates)
+		if err != nil {
+			return nil, err
+		}
+		for i, hit := range hits {
+			r := get(int(hit.Id))
+			r.VectorRank = i + 1
+			if h.metric != nil && hit.V != nil {
+				r.VectorScore = 1 / (1 + h.metric.Distance(hit.V, v))
+			} else {
+				r.VectorScore = 1 - float64(i)/float64(len(hits))
+			}
+		}
+	}
+
+	list := make([]HybridResult, 0, len(results))
+	for _, r := range results {
+		list = append(list, *r)
+	}
+	if h.fusion == fusionWeighted {
+		h.blend(list)
+	} else {
+		for i := range list {
+			list[i].Score = 0
+			if list[i].TextRank > 0 {
+				list[i].Score += 1 / (h.rrfK + float64(list[i].TextRank))
+			}
+			if list[i].VectorRank > 0 {
+				list[i].Score += 1 / (h.rrfK + float64(list[i].VectorRank))
+			}
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].Id < list[j].Id
+	})
+	if len(list) > k {
+		list = list[:k]
+	}
+	return list, nil
+}
+
+func (h *HybridSearcher) blend(list []HybridResult) {
+	textMin, textMax := math.MaxFloat64, -math.MaxFloat64
+	vectorMin, vectorMax := math.MaxFloat64, -math.MaxFloat64
+	for _, r := range list {
+		if r.TextRank > 0 {
+			textMin, textMax = math.Min(textMin, r.TextScore), math.Max(textMax, r.TextScore)
+		}
+		if r.VectorRank > 0 {
+			vectorMin, vectorMax = math.Min(vectorMin, r.VectorScore), math.Max(vectorMax, r.VectorScore)
+		}
+	}
+	normalize := func(x, min, max float64) float64 {
+		if max <= min {
+			return 1
+		}
+		return (x - min) / (max - min)
+	}
+	for i := range list {
+		list[i].Score = 0
+		if list[i].TextRank > 0 {
+			list[i].Score += h.textWeight * normalize(list[i].TextScore, textMin, textMax)
+		}
+		if list[i].VectorRank > 0 {
+			list[i].Score += (1 - h.textWeight) * normalize(list[i].VectorScore, vectorMin, vectorMax)
+		}
+	}
+}
+
+// NewHybridSearcher creates searcher fusing results by RRF with k = 60 by default.
+func NewHybridSearcher(text *MatrixIndex, vectors VectorIndex, opts ...HybridOption) (*HybridSearcher, error) {
+	h := &HybridSearcher{
+		text:       text,
+		vectors:    vectors,
+		fusion:     fusionRRF,
+		rrfK:       60,
+		candidates: 100,
+	}
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
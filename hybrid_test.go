@@ -0,0 +1,78 @@
+package word_index
+
+import (
+	"testing"
+)
+
+func TestHybridSearcher_Search(t *testing.T) {
+	documents := []string{
+		`docker build image`,
+		`docker run container`,
+		`golang concurrency`,
+		`python scripts`,
+	}
+	text := NewMatrixIndex()
+	if err := text.Fit(documents...); err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := NewIndexVector()
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	err = vectors.Fit([]*Vector{
+		{Id: 0, V: []float64{0, 0}},
+		{Id: 1, V: []float64{5, 5}},
+		{Id: 2, V: []float64{1, 0}},
+		{Id: 3, V: []float64{9, 9}},
+	})
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+
+	h, err := NewHybridSearcher(text, vectors)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	result, err := h.Search(`docker`, []float64{0, 0}, 2)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(result) != 2 {
+		t.Fatalf(`len not equals 2, %d`, len(result))
+	}
+	if result[0].Id != 0 {
+		t.Fatalf(`document 0 is found by both indexes and must be first, %d`, result[0].Id)
+	}
+	if result[0].TextRank != 1 || result[0].VectorRank != 1 {
+		t.Fatalf(`wrong ranks %d %d`, result[0].TextRank, result[0].VectorRank)
+	}
+
+	result, err = h.Search(``, []float64{9, 9}, 1)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(result) != 1 || result[0].Id != 3 || result[0].TextRank != 0 {
+		t.Fatalf(`vector only search must return document 3`)
+	}
+
+	h, err = NewHybridSearcher(text, vectors, WithWeightedFusion(1, nil))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	result, err = h.Search(`golang`, []float64{9, 9}, 1)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(result) != 1 || result[0].Id != 2 {
+		t.Fatalf(`text weight 1 must rank text hit first`)
+	}
+	h, _ = NewHybridSearcher(text, vectors, WithWeightedFusion(0, nil))
+	result, _ = h.Search(`golang`, []float64{9, 9}, 1)
+	if len(result) != 1 || result[0].Id != 3 {
+		t.Fatalf(`text weight 0 must rank vector hit first`)
+	}
+
+	if _, err = NewHybridSearcher(text, vectors, WithWeightedFusion(2, nil)); err != ErrInvalidFusion {
+		t.Fatalf(`expected ErrInvalidFusion`)
+	}
+}
@@ -1,27 +1,46 @@
 package word_index
 
 import (
+	"context"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 const (
 	tagAny     = `*`
 	tagAnyRune = '*'
 	emptyFind  = -1
+
+	// contextCheckInterval is the number of scanned documents or merged postings
+	// between checks of context cancellation.
+	contextCheckInterval = 256
 )
 
 //
 type Index interface {
+	Reader
+	Add(...string)
+}
+
+// Reader is the read-only part of Index.
+type Reader interface {
 	Find(string) int
 	FindOff(string, int) int
 	FindAll(string) []int
 	FindAt(int, string) bool
-	Add(...string)
 	DocumentAt(int) (string, bool)
 }
 
+// IndexContext is implemented by indexes supporting cancellation of queries.
+type IndexContext interface {
+	Index
+	FindContext(context.Context, string) (int, error)
+	FindOffContext(context.Context, string, int) (int, error)
+	FindAllContext(context.Context, string) ([]int, error)
+}
+
 //
 type variant struct {
 	query    string
@@ -153,9 +172,24 @@ func (i *indexItem) findBin(query string, variants []string) bool {
 type indexWord struct {
 	data      []*indexItem
 	binSearch bool
+	shards    int
 }
 
 func (i *indexWord) FindAll(str string) []int {
+	result, _ := i.FindAllContext(context.Background(), str)
+	return result
+}
+
+// FindAllContext is FindAll returning ctx.Err() if ctx is done during the scan.
+func (i *indexWord) FindAllContext(ctx context.Context, str string) ([]int, error) {
+	variants := i.queryVariants(str)
+	if i.shards > 1 {
+		return i.findAllSharded(ctx, variants)
+	}
+	return i.findAllRange(ctx, variants, 0, len(i.data))
+}
+
+func (i *indexWord) queryVariants(str string) []*variant {
 	words := strings.Split(strings.ToLower(str), ` `)
 	variants := make([]*variant, len(words))
 	for n, word := range words {
@@ -163,50 +197,99 @@ func (i *indexWord) FindAll(str string) []int {
 		vr := &variant{query: q, variants: v}
 		variants[n] = vr
 	}
+	return variants
+}
+
+// findAllSharded splits data into shards searched concurrently,
+// shards are contiguous so results are concatenated in document order.
+func (i *indexWord) findAllSharded(ctx context.Context, variants []*variant) ([]int, error) {
+	data := i.data
+	chunk := (len(data) + i.shards - 1) / i.shards
+	if chunk == 0 {
+		return []int{}, nil
+	}
+	results := make([][]int, i.shards)
+	err := parallelRange(i.shards, i.shards, func(low, high int) error {
+		for shard := low; shard < high; shard++ {
+			start, end := shard*chunk, (shard+1)*chunk
+			if end > len(data) {
+				end = len(data)
+			}
+			if start < end {
+				r, err := i.findAllRange(ctx, variants, start, end)
+				if err != nil {
+					return err
+				}
+				results[shard] = r
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	size := 0
+	for _, r := range results {
+		size += len(r)
+	}
+	result := make([]int, 0, size)
+	for _, r := range results {
+		result = append(result, r...)
+	}
+	return result, nil
+}
+
+func (i *indexWord) findAllRange(ctx context.Context, variants []*variant, offset, end int) ([]int, error) {
 	result := make([]int, 0)
-	var offset = 0
 	for true {
-		i := i.findOff(variants, offset)
-		if i == emptyFind {
+		index, err := i.findRange(ctx, variants, offset, end)
+		if err != nil {
+			return nil, err
+		}
+		if index == emptyFind {
 			break
 		}
-		result = append(result, i)
-		offset = i + 1
+		result = append(result, index)
+		offset = index + 1
 	}
-	return result
+	return result, nil
 }
 
 func (i *indexWord) FindOff(str string, offset int) int {
-	words := strings.Split(strings.ToLower(str), ` `)
-	variants := make([]*variant, len(words))
-	for n, word := range words {
-		q, v := i.makeVariants(word)
-		vr := &variant{query: q, variants: v}
-		variants[n] = vr
-	}
-	return i.findOff(variants, offset)
+	index, _ := i.FindOffContext(context.Background(), str, offset)
+	return index
 }
 
-func (i *indexWord) findOff(variants []*variant, offset int) int {
+// FindOffContext is FindOff returning ctx.Err() if ctx is done during the scan.
+func (i *indexWord) FindOffContext(ctx context.Context, str string, offset int) (int, error) {
+	return i.findRange(ctx, i.queryVariants(str), offset, len(i.data))
+}
+
+func (i *indexWord) findRange(ctx context.Context, variants []*variant, offset, end int) (int, error) {
 
-	for index := offset; index < len(i.data); index++ {
+	for index := offset; index < end; index++ {
+		if (index-offset)%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return emptyFind, err
+			}
+		}
 		d := i.data[index]
 
 		for _, v := range variants {
 			if i.binSearch {
 				if ok := d.findBin(v.query, v.variants); ok {
-					return index
+					return index, nil
 				}
 			} else {
 				if ok := d.findInterpolation(v.query, v.variants); ok {
-					return index
+					return index, nil
 				}
 			}
 		}
 	}
 
-	return emptyFind
+	return emptyFind, nil
 }
 
 //
@@ -279,6 +362,11 @@ func (i *indexWord) Find(str string) int {
 	return i.FindOff(str, 0)
 }
 
+// FindContext is Find returning ctx.Err() if ctx is done during the scan.
+func (i *indexWord) FindContext(ctx context.Context, str string) (int, error) {
+	return i.FindOffContext(ctx, str, 0)
+}
+
 //
 func (i *indexWord) DocumentAt(index int) (string, bool) {
 	if len(i.data) > index && index >= 0 {
@@ -314,49 +402,86 @@ func NewIndex() Index {
 	return &indexWord{data: make([]*indexItem, 0), binSearch: true}
 }
 
-//
+// NewIndexSharded returns index evaluating FindAll concurrently over shards parts of documents.
+func NewIndexSharded(shards int) Index {
+	return &indexWord{data: make([]*indexItem, 0), binSearch: true, shards: shards}
+}
+
+// indexWordSync is safe for concurrent use. Documents are appended to an immutable
+// prefix of the data slice and the new index is published by atomic pointer swap,
+// so queries never wait for Add and iterate a stable snapshot.
 type indexWordSync struct {
-	indexWord
-	mx sync.RWMutex
+	current   atomic.Value
+	mx        sync.Mutex
+	binSearch bool
+}
+
+func (i *indexWordSync) snapshot() *indexWord {
+	return i.current.Load().(*indexWord)
+}
+
+// Snapshot returns read-only view of the index consistent across several calls.
+func (i *indexWordSync) Snapshot() Reader {
+	return indexWordSnapshot{Reader: i.snapshot()}
 }
 
 func (i *indexWordSync) Add(str ...string) {
+	segment := &indexWord{binSearch: i.binSearch}
+	segment.Add(str...)
+
 	i.mx.Lock()
-	i.indexWord.Add(str...)
-	i.mx.Unlock()
+	defer i.mx.Unlock()
+	current := i.snapshot()
+	// elements after len(current.data) are not visible to readers of current
+	next := &indexWord{data: append(current.data, segment.data...), binSearch: i.binSearch}
+	i.current.Store(next)
 }
 
 func (i *indexWordSync) Find(str string) int {
-	i.mx.RLock()
-	defer i.mx.RUnlock()
-	return i.indexWord.Find(str)
+	return i.snapshot().Find(str)
 }
 
 func (i *indexWordSync) FindOff(str string, offset int) int {
-	i.mx.RLock()
-	defer i.mx.RUnlock()
-	return i.indexWord.FindOff(str, offset)
+	return i.snapshot().FindOff(str, offset)
 }
 
 func (i *indexWordSync) DocumentAt(index int) (string, bool) {
-	i.mx.RLock()
-	defer i.mx.RUnlock()
-	return i.indexWord.DocumentAt(index)
+	return i.snapshot().DocumentAt(index)
 }
 
 func (i *indexWordSync) FindAt(index int, str string) bool {
-	i.mx.RLock()
-	defer i.mx.RUnlock()
-	return i.indexWord.FindAt(index, str)
+	return i.snapshot().FindAt(index, str)
 }
 
 func (i *indexWordSync) FindAll(str string) []int {
-	i.mx.RLock()
-	defer i.mx.RUnlock()
-	return i.indexWord.FindAll(str)
+	return i.snapshot().FindAll(str)
+}
+
+func (i *indexWordSync) FindContext(ctx context.Context, str string) (int, error) {
+	return i.snapshot().FindContext(ctx, str)
+}
+
+func (i *indexWordSync) FindOffContext(ctx context.Context, str string, offset int) (int, error) {
+	return i.snapshot().FindOffContext(ctx, str, offset)
+}
+
+func (i *indexWordSync) FindAllContext(ctx context.Context, str string) ([]int, error) {
+	return i.snapshot().FindAllContext(ctx, str)
 }
 
 //
 func NewIndexSync() Index {
-	return &indexWordSync{indexWord: indexWord{data: make([]*indexItem, 0), binSearch: true}}
+	i := &indexWordSync{binSearch: true}
+	i.current.Store(&indexWord{data: make([]*indexItem, 0), binSearch: true})
+	return i
+}
+
+// Snapshotter is implemented by indexes able to return a consistent read-only view.
+type Snapshotter interface {
+	Snapshot() Reader
+}
+
+// indexWordSnapshot hides the published indexWord, so it can't be asserted and modified.
+type indexWordSnapshot struct {
+	Reader
 }
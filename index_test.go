@@ -1,6 +1,8 @@
 package word_index
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -57,6 +59,33 @@ func BenchmarkIndexBinSync(t *testing.B) {
 	bPlainText(t, bi)
 }
 
+//
+func BenchmarkIndexFindAll(t *testing.B) {
+	bFindAll(t, NewIndex())
+}
+
+//
+func BenchmarkIndexFindAllSharded(t *testing.B) {
+	for _, shards := range []int{2, 4, 8} {
+		t.Run(fmt.Sprintf(`shards_%d`, shards), func(t *testing.B) {
+			bFindAll(t, NewIndexSharded(shards))
+		})
+	}
+}
+
+//
+func bFindAll(t *testing.B, i Index) {
+	for n := 0; n < 500; n++ {
+		i.Add(documents...)
+	}
+
+	t.ResetTimer()
+
+	for j := 0; j < t.N; j++ {
+		i.FindAll(`docker cont* Иванов(а|ой|ым)`)
+	}
+}
+
 //
 func bPlainText(t *testing.B, i Index) {
 	i.Add(documents...)
@@ -85,6 +114,81 @@ func TestIndexBinSyncAtDocument(t *testing.T) {
 	tAtDocument(t, bi)
 }
 
+//
+func TestIndexBinSyncSnapshot(t *testing.T) {
+	bi := NewIndexSync()
+	bi.Add(documents[:10]...)
+	snapshot := bi.(Snapshotter).Snapshot()
+	before := snapshot.FindAll(`docker`)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, document := range documents[10:] {
+			bi.Add(document)
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 50; n++ {
+				for _, id := range bi.FindAll(`docker`) {
+					if _, ok := bi.DocumentAt(id); !ok {
+						t.Errorf(`document %d not found`, id)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(snapshot.FindAll(`docker`)) != len(before) {
+		t.Fatalf(`snapshot changed after Add`)
+	}
+	if _, ok := snapshot.DocumentAt(10); ok {
+		t.Fatalf(`snapshot must not see new documents`)
+	}
+	if len(bi.FindAll(`docker`)) <= len(before) {
+		t.Fatalf(`index must see new documents`)
+	}
+	if _, ok := snapshot.(Index); ok {
+		t.Fatalf(`snapshot must be read-only`)
+	}
+}
+
+//
+func TestIndexSharded(t *testing.T) {
+	bi := NewIndexSharded(4)
+	tIndexPlainText(t, bi)
+
+	bi = NewIndexSharded(3)
+	tIndexMathText(t, bi)
+
+	bi = NewIndexSharded(4)
+	tAtDocument(t, bi)
+
+	single := NewIndex()
+	single.Add(documents...)
+	for _, shards := range []int{2, 3, 7, 64} {
+		bi = NewIndexSharded(shards)
+		bi.Add(documents...)
+		for _, query := range []string{`docker`, `the`, `к`, `c*`, `мер(ы|а)`} {
+			want, got := single.FindAll(query), bi.FindAll(query)
+			if len(want) != len(got) {
+				t.Fatalf(`shards %d, %s: len %d != %d`, shards, query, len(got), len(want))
+			}
+			for n := range want {
+				if want[n] != got[n] {
+					t.Fatalf(`shards %d, %s: results are not in document order`, shards, query)
+				}
+			}
+		}
+	}
+}
+
 //
 func TestIndexBin(t *testing.T) {
 	bi := NewIndex()
@@ -0,0 +1,178 @@
+package word_index
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+var (
+	ErrInvalidIVF = errors.New(`invalid IVF parameters`)
+	ErrNotFitted  = errors.New(`index is not fitted`)
+)
+
+// IndexIVF is an inverted file index: vectors are bucketed by the nearest of nlist
+// coarse centroids and SearchKNN scans only nprobe lists closest to the query.
+// With MetricCosine centroids are trained and compared on L2 normalized vectors,
+// other metrics use Euclidean coarse assignment.
+type IndexIVF struct {
+	nlist      int
+	nprobe     int
+	iterations int
+	seed       int64
+	metric     Metric
+	centroids  [][]float64
+	lists      [][]*Vector
+	itemsMap   map[uint32]int
+}
+
+// Fit trains coarse centroids by k-means on list and fills inverted lists.
+func (ivf *IndexIVF) Fit(list []*Vector) error {
+	vectors := make([][]float64, len(list))
+	for i, v := range list {
+		if len(v.V) != len(list[0].V) {
+			return ErrDimensionMismatch
+		}
+		vectors[i] = ivf.coarse(v.V)
+	}
+	rnd := rand.New(rand.NewSource(ivf.seed))
+	ivf.centroids = kMeans(vectors, ivf.nlist, ivf.iterations, rnd)
+	if ivf.metric == MetricCosine {
+		for _, centroid := range ivf.centroids {
+			normalizeL2(centroid)
+		}
+	}
+	ivf.lists = make([][]*Vector, len(ivf.centroids))
+	ivf.itemsMap = make(map[uint32]int)
+	for i, v := range list {
+		c, _ := nearestCentroid(ivf.centroids, vectors[i])
+		ivf.lists[c] = append(ivf.lists[c], v)
+		ivf.itemsMap[v.Id] = c
+	}
+	return nil
+}
+
+// Add puts v into the list of the nearest centroid, centroids are not retrained.
+func (ivf *IndexIVF) Add(v *Vector) error {
+	if len(ivf.centroids) == 0 {
+		return ErrNotFitted
+	}
+	if _, ok := ivf.itemsMap[v.Id]; ok {
+		return ErrVectorExists
+	}
+	if len(v.V) != len(ivf.centroids[0]) {
+		return ErrDimensionMismatch
+	}
+	c, _ := nearestCentroid(ivf.centroids, ivf.coarse(v.V))
+	ivf.lists[c] = append(ivf.lists[c], v)
+	ivf.itemsMap[v.Id] = c
+	return nil
+}
+
+// coarse returns v as it is compared with centroids, a normalized copy for cosine metric.
+func (ivf *IndexIVF) coarse(v []float64) []float64 {
+	if ivf.metric != MetricCosine {
+		return v
+	}
+	u := append([]float64{}, v...)
+	normalizeL2(u)
+	return u
+}
+
+func (ivf *IndexIVF) Remove(id uint32) error {
+	c, ok := ivf.itemsMap[id]
+	if !ok {
+		return ErrVectorNotFound
+	}
+	for i, v := range ivf.lists[c] {
+		if v.Id == id {
+			ivf.lists[c] = append(ivf.lists[c][:i], ivf.lists[c][i+1:]...)
+			break
+		}
+	}
+	delete(ivf.itemsMap, id)
+	return nil
+}
+
+// Update replaces the vector with the same id, the old vector is kept when v can not be added.
+func (ivf *IndexIVF) Update(v *Vector) error {
+	if _, ok := ivf.itemsMap[v.Id]; !ok {
+		return ErrVectorNotFound
+	}
+	if len(v.V) != len(ivf.centroids[0]) {
+		return ErrDimensionMismatch
+	}
+	if err := ivf.Remove(v.Id); err != nil {
+		return err
+	}
+	return ivf.Add(v)
+}
+
+// SetNProbe changes number of lists scanned by SearchKNN.
+func (ivf *IndexIVF) SetNProbe(nprobe int) error {
+	if nprobe <= 0 {
+		return ErrInvalidIVF
+	}
+	ivf.nprobe = nprobe
+	return nil
+}
+
+// SearchKNN returns k vectors nearest to v from nprobe lists closest to v, closest first.
+func (ivf *IndexIVF) SearchKNN(v []float64, k int) ([]*Vector, error) {
+	if k <= 0 {
+		return []*Vector{}, nil
+	}
+	if len(ivf.centroids) == 0 {
+		return []*Vector{}, nil
+	}
+	if len(v) != len(ivf.centroids[0]) {
+		return nil, ErrDimensionMismatch
+	}
+
+	h := newCandidateHeap(k, len(ivf.itemsMap))
+	for _, c := range ivf.probe(ivf.coarse(v)) {
+		for _, item := range ivf.lists[c] {
+			h.push(vectorCandidate{vector: item, dist: ivf.metric.Distance(item.V, v)}, k)
+		}
+	}
+	candidates := h.sorted()
+	result := make([]*Vector, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.vector
+	}
+	return result, nil
+}
+
+// probe returns indexes of nprobe centroids closest to v.
+func (ivf *IndexIVF) probe(v []float64) []int {
+	lists := make([]int, len(ivf.centroids))
+	dist := make([]float64, len(ivf.centroids))
+	for c, centroid := range ivf.centroids {
+		lists[c] = c
+		dist[c] = distEuclideanSquare(centroid, v)
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return dist[lists[i]] < dist[lists[j]]
+	})
+	if ivf.nprobe < len(lists) {
+		lists = lists[:ivf.nprobe]
+	}
+	return lists
+}
+
+// NewIndexIVF creates IVF index with nlist coarse centroids, metric nil means Euclidean.
+func NewIndexIVF(nlist, nprobe int, metric Metric) (*IndexIVF, error) {
+	if nlist <= 0 || nprobe <= 0 {
+		return nil, ErrInvalidIVF
+	}
+	if metric == nil {
+		metric = MetricEuclidean
+	}
+	return &IndexIVF{
+		nlist:      nlist,
+		nprobe:     nprobe,
+		iterations: 25,
+		seed:       1,
+		metric:     metric,
+	}, nil
+}
@@ -0,0 +1,168 @@
+package word_index
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var (
+	_ VectorIndex = (*IndexVector)(nil)
+	_ VectorIndex = (*IndexIVF)(nil)
+)
+
+func TestIndexIVF_SearchKNN(t *testing.T) {
+	if _, err := NewIndexIVF(0, 1, nil); err != ErrInvalidIVF {
+		t.Fatalf(`expected ErrInvalidIVF`)
+	}
+	rnd := rand.New(rand.NewSource(7))
+	list := make([]*Vector, 1000)
+	for i := range list {
+		v := make([]float64, 4)
+		for j := range v {
+			v[j] = rnd.Float64()
+		}
+		list[i] = NewVector(uint32(i), v, nil)
+	}
+
+	indexes := make([]VectorIndex, 0)
+	iv, err := NewIndexVector()
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	indexes = append(indexes, iv)
+	ivf, err := NewIndexIVF(16, 16, nil)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	indexes = append(indexes, ivf)
+	for _, index := range indexes {
+		if err = index.Fit(list); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+	}
+
+	const k = 5
+	for n := 0; n < 20; n++ {
+		q := []float64{rnd.Float64(), rnd.Float64(), rnd.Float64(), rnd.Float64()}
+		want, err := indexes[0].SearchKNN(q, k)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		got, err := indexes[1].SearchKNN(q, k)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		if len(got) != k {
+			t.Fatalf(`len not equals %d, %d`, k, len(got))
+		}
+		for i := range want {
+			if want[i].Id != got[i].Id {
+				t.Fatalf(`all lists probed, result must be exact: %d != %d`, want[i].Id, got[i].Id)
+			}
+		}
+	}
+
+	if err = ivf.SetNProbe(2); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	got, err := ivf.SearchKNN(list[10].V, 1)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(got) != 1 || got[0].Id != 10 {
+		t.Fatalf(`vector 10 not found in its own list`)
+	}
+
+	if err = ivf.Remove(10); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	got, _ = ivf.SearchKNN(list[10].V, 1)
+	if len(got) == 1 && got[0].Id == 10 {
+		t.Fatalf(`removed vector found`)
+	}
+	if err = ivf.Add(list[10]); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = ivf.Add(list[10]); err != ErrVectorExists {
+		t.Fatalf(`expected ErrVectorExists`)
+	}
+	if err = ivf.Update(NewVector(10, []float64{1, 2, 3}, nil)); err != ErrDimensionMismatch {
+		t.Fatalf(`expected ErrDimensionMismatch, %v`, err)
+	}
+	if _, ok := ivf.itemsMap[10]; !ok {
+		t.Fatalf(`vector must be kept after failed update`)
+	}
+	if err = ivf.Update(NewVector(1000, list[10].V, nil)); err != ErrVectorNotFound {
+		t.Fatalf(`expected ErrVectorNotFound`)
+	}
+	if err = ivf.SetNProbe(16); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if got, _ = ivf.SearchKNN(list[0].V, 1<<40); len(got) != len(list) {
+		t.Fatalf(`huge k must return all %d vectors, %d`, len(list), len(got))
+	}
+}
+
+func TestIndexVector_MetricCosine(t *testing.T) {
+	iv, err := NewIndexVector(WithMetric(MetricCosine))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	err = iv.Fit([]*Vector{
+		{Id: 1, V: []float64{1, 0}},
+		{Id: 2, V: []float64{10, 1}},
+		{Id: 3, V: []float64{0, 1}},
+	})
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	got, err := iv.SearchKNN([]float64{100, 0}, 2)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(got) != 2 || got[0].Id != 1 || got[1].Id != 2 {
+		t.Fatalf(`wrong cosine order`)
+	}
+}
+
+func TestIndexIVF_MetricCosine(t *testing.T) {
+	rnd := rand.New(rand.NewSource(8))
+	list := make([]*Vector, 800)
+	for i := range list {
+		angle := float64(i%8)*math.Pi/4 + rnd.Float64()*0.2
+		r := 1 + rnd.Float64()*100
+		list[i] = NewVector(uint32(i), []float64{r * math.Cos(angle), r * math.Sin(angle)}, nil)
+	}
+	exact, _ := NewIndexVector(WithMetric(MetricCosine))
+	exact.Fit(list)
+	ivf, _ := NewIndexIVF(8, 1, MetricCosine)
+	if err := ivf.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+
+	const k = 10
+	hits := 0
+	for n := 0; n < 50; n++ {
+		angle := float64(n%8)*math.Pi/4 + 0.1
+		r := 1 + rnd.Float64()*100
+		q := []float64{r * math.Cos(angle), r * math.Sin(angle)}
+		want, _ := exact.SearchKNN(q, k)
+		got, err := ivf.SearchKNN(q, k)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		ids := make(map[uint32]struct{})
+		for _, v := range want {
+			ids[v.Id] = struct{}{}
+		}
+		for _, v := range got {
+			if _, ok := ids[v.Id]; ok {
+				hits++
+			}
+		}
+	}
+	if recall := float64(hits) / (50 * k); recall < 0.9 {
+		t.Fatalf(`cosine recall with one probe %.2f < 0.9`, recall)
+	}
+}
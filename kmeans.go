@@ -0,0 +1,97 @@
+package word_index
+
+import (
+	"math"
+	"math/rand"
+)
+
+// kMeans clusters data into k centroids with Lloyd iterations.
+// Initial centroids are chosen by k-means++ seeding from rnd.
+func kMeans(data [][]float64, k, iterations int, rnd *rand.Rand) [][]float64 {
+	if len(data) == 0 || k <= 0 {
+		return [][]float64{}
+	}
+	if k > len(data) {
+		k = len(data)
+	}
+	dim := len(data[0])
+
+	centroids := make([][]float64, 0, k)
+	centroids = append(centroids, append([]float64{}, data[rnd.Intn(len(data))]...))
+	dist := make([]float64, len(data))
+	for i := range dist {
+		dist[i] = math.MaxFloat64
+	}
+	for len(centroids) < k {
+		last := centroids[len(centroids)-1]
+		sum := float64(0)
+		for i, v := range data {
+			if d := distEuclideanSquare(v, last); d < dist[i] {
+				dist[i] = d
+			}
+			sum += dist[i]
+		}
+		next := rnd.Intn(len(data))
+		if sum > 0 {
+			target := rnd.Float64() * sum
+			for i, d := range dist {
+				target -= d
+				if target <= 0 {
+					next = i
+					break
+				}
+			}
+		}
+		centroids = append(centroids, append([]float64{}, data[next]...))
+	}
+
+	assign := make([]int, len(data))
+	counts := make([]int, k)
+	for it := 0; it < iterations; it++ {
+		changed := false
+		for i, v := range data {
+			c, _ := nearestCentroid(centroids, v)
+			if c != assign[i] || it == 0 {
+				changed = true
+			}
+			assign[i] = c
+		}
+		if !changed {
+			break
+		}
+		for c := range centroids {
+			counts[c] = 0
+			for j := range centroids[c] {
+				centroids[c][j] = 0
+			}
+		}
+		for i, v := range data {
+			c := assign[i]
+			counts[c]++
+			for j := 0; j < dim; j++ {
+				centroids[c][j] += v[j]
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				copy(centroids[c], data[rnd.Intn(len(data))])
+				continue
+			}
+			for j := range centroids[c] {
+				centroids[c][j] /= float64(counts[c])
+			}
+		}
+	}
+	return centroids
+}
+
+// nearestCentroid returns index of the centroid closest to v and squared distance to it.
+func nearestCentroid(centroids [][]float64, v []float64) (int, float64) {
+	best, bestDist := 0, math.MaxFloat64
+	for c, centroid := range centroids {
+		if d := distEuclideanSquare(v, centroid); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best, bestDist
+}
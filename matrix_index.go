@@ -1,6 +1,8 @@
 package word_index
 
 import (
+	"context"
+	"math"
 	"sort"
 	"strings"
 )
@@ -8,6 +10,8 @@ import (
 type MatrixIndex struct {
 	items     []*matrixIndexItem
 	documents []string
+	// static are static scores of documents by id added to relevance in QueryRanked
+	static []float64
 }
 
 func (m *MatrixIndex) Find(query string) int {
@@ -29,6 +33,36 @@ func (m *MatrixIndex) FindAll(query string) []int {
 	return m.Query(query)
 }
 
+// FindContext is Find returning ctx.Err() if ctx is done during the query.
+func (m *MatrixIndex) FindContext(ctx context.Context, query string) (int, error) {
+	result, err := m.QueryContext(ctx, query)
+	if err != nil {
+		return emptyFind, err
+	}
+	if len(result) > 0 {
+		return result[0], nil
+	}
+	return emptyFind, nil
+}
+
+// FindOffContext is FindOff returning ctx.Err() if ctx is done during the query.
+func (m *MatrixIndex) FindOffContext(ctx context.Context, query string, low int) (int, error) {
+	result, err := m.QueryContext(ctx, query)
+	if err != nil {
+		return emptyFind, err
+	}
+	i := sort.SearchInts(result, low)
+	if i < len(result) && result[i] == low {
+		return low, nil
+	}
+	return emptyFind, nil
+}
+
+// FindAllContext is FindAll returning ctx.Err() if ctx is done during the query.
+func (m *MatrixIndex) FindAllContext(ctx context.Context, query string) ([]int, error) {
+	return m.QueryContext(ctx, query)
+}
+
 func (m *MatrixIndex) FindAt(index int, query string) bool {
 	result := m.Query(query)
 	if len(result) == 0 {
@@ -53,7 +87,7 @@ func (m *MatrixIndex) Add(documents ...string) {
 }
 
 func (m *MatrixIndex) DocumentAt(index int) (string, bool) {
-	if len(m.documents) > index {
+	if len(m.documents) > index && index >= 0 {
 		return m.documents[index], true
 	}
 	return "", false
@@ -99,6 +133,9 @@ func (m *MatrixIndex) Fit(documents ...string) error {
 
 	m.items = items
 	m.documents = documents
+	if len(m.static) > len(documents) {
+		m.static = m.static[:len(documents)]
+	}
 	return nil
 }
 
@@ -107,24 +144,175 @@ func (m *MatrixIndex) Query(query string) []int {
 }
 
 func (m *MatrixIndex) QueryAndOr(query string, useAnd bool) []int {
+	result, _ := m.QueryAndOrContext(context.Background(), query, useAnd)
+	return result
+}
+
+// QueryContext is Query returning ctx.Err() if ctx is done during the query.
+func (m *MatrixIndex) QueryContext(ctx context.Context, query string) ([]int, error) {
+	return m.QueryAndOrContext(ctx, query, false)
+}
+
+// QueryAndOrContext is QueryAndOr returning ctx.Err() if ctx is done
+// while dictionary terms are expanded or postings are merged.
+func (m *MatrixIndex) QueryAndOrContext(ctx context.Context, query string, useAnd bool) ([]int, error) {
 	words := strings.Split(strings.ToLower(query), ` `)
 	high := len(m.items) - 1
 	results := make([][]int, len(words))
 	for i, word := range words {
 		q, variants := makeVariants(word)
-		results[i] = m.findBin(q, variants, 0, high)
+		result, err := m.findBinContext(ctx, q, variants, 0, high)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
 	}
 	if useAnd {
-		return MergeOrderedArrayAnd(results)
+		return mergeOrderedArrayAnd(ctx, results)
 	}
-	return MergeOrderedArray(results)
+	return mergeOrderedArray(ctx, results)
+}
+
+// ScoredDocument is a document id with relevance score, greater score is better.
+type ScoredDocument struct {
+	Id    int
+	Score float64
+}
+
+// QueryRanked returns documents matching any query word ordered by relevance:
+// sum of boost*idf of matched query words plus static score of document, ties are ordered by id.
+// Boost is set by word suffix as in docker^3, a word with invalid boost is searched as is.
+func (m *MatrixIndex) QueryRanked(query string) []ScoredDocument {
+	words := strings.Split(strings.ToLower(query), ` `)
+	high := len(m.items) - 1
+	scores := make(map[int]float64)
+	for _, word := range words {
+		word, boost := m.boost(word)
+		q, variants := makeVariants(word)
+		result := m.findBin(q, variants, 0, high)
+		if len(result) == 0 {
+			continue
+		}
+		score := boost * m.idf(len(result))
+		for _, id := range result {
+			scores[id] += score
+		}
+	}
+	for id := range scores {
+		scores[id] += m.staticScore(id)
+	}
+
+	return rankScores(scores)
+}
+
+// boost splits word of ranked query into term and boost.
+func (m *MatrixIndex) boost(word string) (string, float64) {
+	w, boost, err := parseBoost(word)
+	if err != nil {
+		return word, 1
+	}
+	return w, boost
+}
+
+// SetStaticScore sets static score of document id, e.g. recency or popularity,
+// added to relevance in QueryRanked. Scores are kept by id when documents are added.
+func (m *MatrixIndex) SetStaticScore(id int, score float64) error {
+	if id < 0 || id >= len(m.documents) {
+		return ErrDocumentNotFound
+	}
+	if !isFinite(score) {
+		return ErrInvalidScore
+	}
+	for len(m.static) <= id {
+		m.static = append(m.static, 0)
+	}
+	m.static[id] = score
+	return nil
+}
+
+func (m *MatrixIndex) staticScore(id int) float64 {
+	if id < len(m.static) {
+		return m.static[id]
+	}
+	return 0
+}
+
+// rankScores orders documents by score, ties are ordered by id.
+func rankScores(scores map[int]float64) []ScoredDocument {
+	ranked := make([]ScoredDocument, 0, len(scores))
+	for id, score := range scores {
+		ranked = append(ranked, ScoredDocument{Id: id, Score: score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Id < ranked[j].Id
+	})
+	return ranked
+}
+
+func (m *MatrixIndex) idf(df int) float64 {
+	return idf(len(m.documents), df)
+}
+
+func isFinite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+// idf is inverse document frequency of a term found in df of n documents.
+func idf(n, df int) float64 {
+	return math.Log(1 + float64(n)/float64(df))
 }
 
 func (m *MatrixIndex) findBin(word string, variants []string, low, high int) []int {
-	w := strings.TrimSpace(word)
-	if len(w) < 2 {
-		return []int{}
+	result, _ := m.findBinContext(context.Background(), word, variants, low, high)
+	return result
+}
+
+func (m *MatrixIndex) findBinContext(ctx context.Context, word string, variants []string, low, high int) ([]int, error) {
+	results, err := m.findTermsContext(ctx, word, variants, low, high)
+	if err != nil {
+		return nil, err
+	}
+	return mergeOrderedArray(ctx, results)
+}
+
+// findTerms returns postings of all dictionary terms matching word.
+func (m *MatrixIndex) findTerms(word string, variants []string) [][]int {
+	results, _ := m.findTermsContext(context.Background(), word, variants, 0, len(m.items)-1)
+	return results
+}
+
+func (m *MatrixIndex) findTermsContext(ctx context.Context, word string, variants []string, low, high int) ([][]int, error) {
+	if len(strings.TrimSpace(word)) < 2 {
+		return [][]int{}, nil
 	}
+	low = m.lowerBoundTerm(word, low, high)
+
+	results := make([][]int, 0)
+	for low < len(m.items) && m.compareWord(m.items[low].word, word, variants) {
+		if len(results)%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		/*if len(result) == 0 {
+				result = m.items[low].index
+			} else {
+				result = MergeOrderedArray([][]int{result, m.items[low].index})
+		} */
+		results = append(results, m.items[low].index)
+
+		low++
+	}
+
+	return results, nil
+}
+
+// lowerBoundTerm returns position of the first dictionary term which may match word.
+func (m *MatrixIndex) lowerBoundTerm(word string, low, high int) int {
+	w := strings.TrimSpace(word)
 	if w[len(w)-1] == tagAnyRune {
 		w = w[:len(w)-1]
 	} else if w[len(w)-1] == ')' {
@@ -143,23 +331,15 @@ func (m *MatrixIndex) findBin(word string, variants []string, low, high int) []i
 			high = median - 1
 		}
 	}
-
-	results := make([][]int, 0)
-	for low < len(m.items) && m.compareWord(m.items[low].word, word, variants) {
-		/*if len(result) == 0 {
-				result = m.items[low].index
-			} else {
-				result = MergeOrderedArray([][]int{result, m.items[low].index})
-		} */
-		results = append(results, m.items[low].index)
-
-		low++
-	}
-
-	return MergeOrderedArray(results)
+	return low
 }
 
 func (m *MatrixIndex) compareWord(word, query string, variants []string) bool {
+	return matchWord(word, query, variants)
+}
+
+// matchWord reports whether word equals query, one of its variants or has prefix of query ending with *.
+func matchWord(word, query string, variants []string) bool {
 	if word == query {
 		return true
 	}
@@ -187,6 +367,11 @@ func (m *MatrixIndex) compareWord(word, query string, variants []string) bool {
 }
 
 func MergeOrderedArray(a [][]int) []int {
+	b, _ := mergeOrderedArray(context.Background(), a)
+	return b
+}
+
+func mergeOrderedArray(ctx context.Context, a [][]int) ([]int, error) {
 	maxLen := 0
 	maxValue := 0
 
@@ -207,7 +392,12 @@ func MergeOrderedArray(a [][]int) []int {
 	b := make([]int, 0, maxLen)
 	lastIndex := -1
 	minValue := maxValue
-	for true {
+	for n := 0; true; n++ {
+		if n%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 
 		minIndexResult := -1
 		for j := 0; j < len(a); j++ {
@@ -233,10 +423,15 @@ func MergeOrderedArray(a [][]int) []int {
 		//a[minIndexResult] = a[minIndexResult][1:]
 		offsets[minIndexResult]++
 	}
-	return b
+	return b, nil
 }
 
 func MergeOrderedArrayAnd(a [][]int) []int {
+	b, _ := mergeOrderedArrayAnd(context.Background(), a)
+	return b
+}
+
+func mergeOrderedArrayAnd(ctx context.Context, a [][]int) ([]int, error) {
 	b := make([]int, 0)
 	minIndex := 0
 	for i := 1; i < len(a); i++ {
@@ -246,7 +441,11 @@ func MergeOrderedArrayAnd(a [][]int) []int {
 	}
 	offsets := make([]int, len(a))
 	for i, v := range a[minIndex] {
-		_ = i
+		if i%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		has := true
 		for j := 0; j < len(a); j++ {
 			if j == minIndex {
@@ -269,7 +468,7 @@ func MergeOrderedArrayAnd(a [][]int) []int {
 			b = append(b, v)
 		}
 	}
-	return b
+	return b, nil
 }
 
 type matrixIndexItem struct {
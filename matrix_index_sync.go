@@ -0,0 +1,143 @@
+package word_index
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// MatrixIndexSync is safe for concurrent use. Fit and Add build a new MatrixIndex
+// and publish it by atomic pointer swap, so queries never wait for writers
+// and always see a consistent snapshot.
+type MatrixIndexSync struct {
+	current atomic.Value
+	mx      sync.Mutex
+}
+
+// MatrixReader is the read-only part of MatrixIndex.
+type MatrixReader interface {
+	Reader
+	FindContext(context.Context, string) (int, error)
+	FindOffContext(context.Context, string, int) (int, error)
+	FindAllContext(context.Context, string) ([]int, error)
+	Query(string) []int
+	QueryAndOr(string, bool) []int
+	QueryContext(context.Context, string) ([]int, error)
+	QueryAndOrContext(context.Context, string, bool) ([]int, error)
+	QueryRanked(string) []ScoredDocument
+	Cursor(string) Cursor
+	CursorAndOr(string, bool) Cursor
+	Explain(string, int) (*Explanation, error)
+	Search(string, SearchOptions) SearchResult
+}
+
+// matrixIndexSnapshot hides the published MatrixIndex, so it can't be asserted and modified.
+type matrixIndexSnapshot struct {
+	MatrixReader
+}
+
+// Snapshot returns read-only view of the current index, documents added later are not visible.
+func (m *MatrixIndexSync) Snapshot() MatrixReader {
+	return matrixIndexSnapshot{MatrixReader: m.snapshot()}
+}
+
+func (m *MatrixIndexSync) snapshot() *MatrixIndex {
+	return m.current.Load().(*MatrixIndex)
+}
+
+func (m *MatrixIndexSync) Fit(documents ...string) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	return m.fit(documents)
+}
+
+func (m *MatrixIndexSync) fit(documents []string) error {
+	next := NewMatrixIndex()
+	next.static = append([]float64{}, m.snapshot().static...)
+	if err := next.Fit(documents...); err != nil {
+		return err
+	}
+	m.current.Store(next)
+	return nil
+}
+
+// SetStaticScore publishes a copy of the index with static score of document id, see MatrixIndex.SetStaticScore.
+func (m *MatrixIndexSync) SetStaticScore(id int, score float64) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	next := *m.snapshot()
+	next.static = append([]float64{}, next.static...)
+	if err := next.SetStaticScore(id, score); err != nil {
+		return err
+	}
+	m.current.Store(&next)
+	return nil
+}
+
+func (m *MatrixIndexSync) Add(documents ...string) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	current := m.snapshot().documents
+	all := make([]string, 0, len(current)+len(documents))
+	all = append(all, current...)
+	all = append(all, documents...)
+	m.fit(all)
+}
+
+func (m *MatrixIndexSync) Find(query string) int {
+	return m.snapshot().Find(query)
+}
+
+func (m *MatrixIndexSync) FindOff(query string, low int) int {
+	return m.snapshot().FindOff(query, low)
+}
+
+func (m *MatrixIndexSync) FindAll(query string) []int {
+	return m.snapshot().FindAll(query)
+}
+
+func (m *MatrixIndexSync) FindAt(index int, query string) bool {
+	return m.snapshot().FindAt(index, query)
+}
+
+func (m *MatrixIndexSync) DocumentAt(index int) (string, bool) {
+	return m.snapshot().DocumentAt(index)
+}
+
+func (m *MatrixIndexSync) Query(query string) []int {
+	return m.snapshot().Query(query)
+}
+
+func (m *MatrixIndexSync) QueryAndOr(query string, useAnd bool) []int {
+	return m.snapshot().QueryAndOr(query, useAnd)
+}
+
+func (m *MatrixIndexSync) QueryRanked(query string) []ScoredDocument {
+	return m.snapshot().QueryRanked(query)
+}
+
+func (m *MatrixIndexSync) FindContext(ctx context.Context, query string) (int, error) {
+	return m.snapshot().FindContext(ctx, query)
+}
+
+func (m *MatrixIndexSync) FindOffContext(ctx context.Context, query string, low int) (int, error) {
+	return m.snapshot().FindOffContext(ctx, query, low)
+}
+
+func (m *MatrixIndexSync) FindAllContext(ctx context.Context, query string) ([]int, error) {
+	return m.snapshot().FindAllContext(ctx, query)
+}
+
+func (m *MatrixIndexSync) QueryContext(ctx context.Context, query string) ([]int, error) {
+	return m.snapshot().QueryContext(ctx, query)
+}
+
+func (m *MatrixIndexSync) QueryAndOrContext(ctx context.Context, query string, useAnd bool) ([]int, error) {
+	return m.snapshot().QueryAndOrContext(ctx, query, useAnd)
+}
+
+func NewMatrixIndexSync() *MatrixIndexSync {
+	m := &MatrixIndexSync{}
+	m.current.Store(NewMatrixIndex())
+	return m
+}
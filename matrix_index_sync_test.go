@@ -0,0 +1,56 @@
+package word_index
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestMatrixIndexSync(t *testing.T) {
+	bi := NewMatrixIndexSync()
+	tIndexPlainText(t, bi)
+
+	bi = NewMatrixIndexSync()
+	tIndexMathText(t, bi)
+
+	bi = NewMatrixIndexSync()
+	tAtDocument(t, bi)
+}
+
+func TestMatrixIndexSync_Concurrent(t *testing.T) {
+	index := NewMatrixIndexSync()
+	if err := index.Fit(documents[:10]...); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, document := range documents[10:] {
+			index.Add(document)
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				snapshot := index.Snapshot()
+				for _, id := range snapshot.Query(`docker`) {
+					if _, ok := snapshot.DocumentAt(id); !ok {
+						t.Errorf(`document %d of snapshot not found`, id)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if _, ok := index.Snapshot().(Index); ok {
+		t.Fatalf(`snapshot must be read-only`)
+	}
+	expected := NewMatrixIndex()
+	expected.Fit(documents...)
+	if len(index.Query(`docker`)) != len(expected.Query(`docker`)) {
+		t.Fatalf(`len not equals %d, %d`, len(expected.Query(`docker`)), len(index.Query(`docker`)))
+	}
+}
@@ -2,6 +2,7 @@ package word_index
 
 import (
 	"fmt"
+	"math"
 	"testing"
 )
 
@@ -238,3 +239,94 @@ func TestMatrixIndex_QueryAndOr(t *testing.T) {
 		t.Fatalf(``)
 	}
 }
+
+func TestMatrixIndex_QueryRanked(t *testing.T) {
+	documents := []string{
+		`abc zyz test site`,
+		`test best aaa`,
+		`anna vera zoom site`,
+		`aaa zet zzzz`,
+		`This site test can’t be reached`,
+		`rare site`,
+	}
+
+	index := NewMatrixIndex()
+	err := index.Fit(documents...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := index.QueryRanked(`test site rare`)
+	if len(result) != 5 {
+		t.Fatalf(`len not equals 5, %d`, len(result))
+	}
+	if result[0].Id != 5 {
+		t.Fatalf(`rare word must rank first, %d`, result[0].Id)
+	}
+	if result[1].Id != 0 || result[2].Id != 4 {
+		t.Fatalf(`documents with both words must follow, %v`, result)
+	}
+	for i := 1; i < len(result); i++ {
+		if result[i-1].Score < result[i].Score {
+			t.Fatalf(`result is not ordered by score`)
+		}
+	}
+	if len(index.QueryRanked(`unknown`)) != 0 {
+		t.Fatalf(``)
+	}
+}
+
+func TestMatrixIndex_QueryRankedBoost(t *testing.T) {
+	index := NewMatrixIndex()
+	if err := index.Fit(`docker image`, `docker run`, `kubernetes pod`, `docker kubernetes`); err != nil {
+		t.Fatal(err)
+	}
+
+	result := index.QueryRanked(`docker kubernetes`)
+	if result[0].Id != 3 {
+		t.Fatalf(`document with both words must rank first, %v`, result)
+	}
+	result = index.QueryRanked(`docker kubernetes^3`)
+	if result[0].Id != 3 || result[1].Id != 2 {
+		t.Fatalf(`boosted word must rank first, %v`, result)
+	}
+	if len(index.QueryRanked(`docker^NaN`)) != 0 {
+		t.Fatalf(`word with invalid boost must be searched as is`)
+	}
+
+	if err := index.SetStaticScore(1, 10); err != nil {
+		t.Fatal(err)
+	}
+	result = index.QueryRanked(`docker kubernetes`)
+	if result[0].Id != 1 {
+		t.Fatalf(`static score must rank document first, %v`, result)
+	}
+	if len(index.QueryRanked(`unknown`)) != 0 {
+		t.Fatalf(`static score must not match documents`)
+	}
+	e, err := index.Explain(`docker kubernetes^3`, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range index.QueryRanked(`docker kubernetes^3`) {
+		if r.Id == 1 && math.Abs(r.Score-e.Score) > 1e-9 {
+			t.Fatalf(`explain score %f != %f`, e.Score, r.Score)
+		}
+	}
+	if err = index.SetStaticScore(4, 1); err != ErrDocumentNotFound {
+		t.Fatalf(`expected ErrDocumentNotFound`)
+	}
+	if err = index.SetStaticScore(0, math.Inf(1)); err != ErrInvalidScore {
+		t.Fatalf(`expected ErrInvalidScore`)
+	}
+
+	sync := NewMatrixIndexSync()
+	sync.Add(`docker image`, `docker run`)
+	if err = sync.SetStaticScore(1, 10); err != nil {
+		t.Fatal(err)
+	}
+	sync.Add(`docker compose`)
+	if result = sync.QueryRanked(`docker`); result[0].Id != 1 {
+		t.Fatalf(`static score must be kept on Add, %v`, result)
+	}
+}
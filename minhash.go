@@ -0,0 +1,240 @@
+package word_index
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidMinHash = errors.New(`invalid MinHash parameters`)
+)
+
+// MinHash builds signatures of documents over word shingles,
+// the share of equal signature values estimates Jaccard similarity of shingle sets.
+type MinHash struct {
+	shingle int
+	seeds   []uint64
+}
+
+// NewMinHash creates generator of signatures with numHashes values over shingles of shingle words.
+func NewMinHash(numHashes, shingle int) (*MinHash, error) {
+	if numHashes <= 0 || shingle <= 0 {
+		return nil, ErrInvalidMinHash
+	}
+	rnd := rand.New(rand.NewSource(1))
+	seeds := make([]uint64, numHashes)
+	for i := range seeds {
+		seeds[i] = rnd.Uint64()
+	}
+	return &MinHash{shingle: shingle, seeds: seeds}, nil
+}
+
+// Signature returns MinHash signature of document, words are split as in MatrixIndex.Fit.
+func (m *MinHash) Signature(document string) []uint64 {
+	signature := make([]uint64, len(m.seeds))
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+	for _, shingle := range m.shingles(document) {
+		for i, seed := range m.seeds {
+			if h := splitMix64(shingle ^ seed); h < signature[i] {
+				signature[i] = h
+			}
+		}
+	}
+	return signature
+}
+
+func (m *MinHash) shingles(document string) []uint64 {
+	words := make([]string, 0)
+	for _, word := range strings.Split(strings.ToLower(document), ` `) {
+		if len(word) > 0 {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return []uint64{}
+	}
+	n := len(words) - m.shingle + 1
+	if n < 1 {
+		n = 1
+	}
+	result := make([]uint64, n)
+	for i := 0; i < n; i++ {
+		end := i + m.shingle
+		if end > len(words) {
+			end = len(words)
+		}
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:end], ` `)))
+		result[i] = h.Sum64()
+	}
+	return result
+}
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	return x ^ (x >> 31)
+}
+
+// EstimateJaccard returns share of equal values of two signatures.
+func EstimateJaccard(a, b []uint64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+// LSHIndex groups MinHash signatures by bands, documents sharing any band bucket
+// are candidates for near-duplicates. Document ids are positions as in MatrixIndex.
+type LSHIndex struct {
+	minHash    *MinHash
+	bands      int
+	rows       int
+	threshold  float64
+	buckets    []map[uint64][]int
+	signatures [][]uint64
+}
+
+// NewLSHIndex creates index with bands of signature, number of signature values must be
+// divisible by bands. threshold is the minimal estimated Jaccard similarity of DuplicateClusters.
+func NewLSHIndex(minHash *MinHash, bands int, threshold float64) (*LSHIndex, error) {
+	if minHash == nil || bands <= 0 || len(minHash.seeds)%bands != 0 || threshold < 0 || threshold > 1 {
+		return nil, ErrInvalidMinHash
+	}
+	l := &LSHIndex{
+		minHash:   minHash,
+		bands:     bands,
+		rows:      len(minHash.seeds) / bands,
+		threshold: threshold,
+	}
+	l.reset()
+	return l, nil
+}
+
+func (l *LSHIndex) reset() {
+	l.buckets = make([]map[uint64][]int, l.bands)
+	for i := range l.buckets {
+		l.buckets[i] = make(map[uint64][]int)
+	}
+	l.signatures = make([][]uint64, 0)
+}
+
+// Fit replaces indexed documents.
+func (l *LSHIndex) Fit(documents ...string) {
+	l.reset()
+	l.Add(documents...)
+}
+
+// FitMatrixIndex indexes documents of m, so ids of both indexes are equal.
+func (l *LSHIndex) FitMatrixIndex(m *MatrixIndex) {
+	l.Fit(m.documents...)
+}
+
+func (l *LSHIndex) Add(documents ...string) {
+	buf := make([]byte, 8*l.rows)
+	for _, document := range documents {
+		id := len(l.signatures)
+		signature := l.minHash.Signature(document)
+		l.signatures = append(l.signatures, signature)
+		for b := 0; b < l.bands; b++ {
+			key := l.bandKey(signature, b, buf)
+			l.buckets[b][key] = append(l.buckets[b][key], id)
+		}
+	}
+}
+
+// bandKey returns bucket key of band b, buf must have 8*rows bytes.
+func (l *LSHIndex) bandKey(signature []uint64, b int, buf []byte) uint64 {
+	for r := 0; r < l.rows; r++ {
+		binary.LittleEndian.PutUint64(buf[r*8:], signature[b*l.rows+r])
+	}
+	h := fnv.New64a()
+	h.Write(buf)
+	return h.Sum64()
+}
+
+// NearDuplicates returns ids of documents with estimated Jaccard similarity to docID
+// not less than threshold, docID itself is not included.
+func (l *LSHIndex) NearDuplicates(docID int, threshold float64) []int {
+	result := make([]int, 0)
+	if docID < 0 || docID >= len(l.signatures) {
+		return result
+	}
+	for id := range l.candidates(docID) {
+		if EstimateJaccard(l.signatures[docID], l.signatures[id]) >= threshold {
+			result = append(result, id)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+func (l *LSHIndex) candidates(docID int) map[int]struct{} {
+	buf := make([]byte, 8*l.rows)
+	signature := l.signatures[docID]
+	result := make(map[int]struct{})
+	for b := 0; b < l.bands; b++ {
+		for _, id := range l.buckets[b][l.bandKey(signature, b, buf)] {
+			if id != docID {
+				result[id] = struct{}{}
+			}
+		}
+	}
+	return result
+}
+
+// DuplicateClusters returns groups of two or more documents connected by near-duplicate
+// relation with the index threshold. Ids in groups and groups are ordered.
+func (l *LSHIndex) DuplicateClusters() [][]int {
+	parent := make([]int, len(l.signatures))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	for id := range l.signatures {
+		for _, other := range l.NearDuplicates(id, l.threshold) {
+			a, b := find(id), find(other)
+			if a < b {
+				parent[b] = a
+			} else if b < a {
+				parent[a] = b
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for id := range parent {
+		root := find(id)
+		groups[root] = append(groups[root], id)
+	}
+	result := make([][]int, 0)
+	for _, group := range groups {
+		if len(group) > 1 {
+			result = append(result, group)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
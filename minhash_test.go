@@ -0,0 +1,75 @@
+package word_index
+
+import (
+	"testing"
+)
+
+func TestMinHash_Signature(t *testing.T) {
+	if _, err := NewMinHash(0, 1); err != ErrInvalidMinHash {
+		t.Fatalf(`expected ErrInvalidMinHash`)
+	}
+	m, err := NewMinHash(200, 2)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	a := m.Signature(`the quick brown fox jumps over the lazy dog`)
+	b := m.Signature(`The quick  brown fox jumps over the lazy dog`)
+	if EstimateJaccard(a, b) != 1 {
+		t.Fatalf(`equal documents must have equal signatures`)
+	}
+	// 8 shingles, 7 common of 9 distinct
+	c := m.Signature(`the quick brown fox jumps over the lazy cat`)
+	if j := EstimateJaccard(a, c); j < 0.65 || j > 0.9 {
+		t.Fatalf(`estimated jaccard %.2f far from 0.78`, j)
+	}
+	d := m.Signature(`completely different text`)
+	if j := EstimateJaccard(a, d); j > 0.1 {
+		t.Fatalf(`estimated jaccard %.2f must be near 0`, j)
+	}
+}
+
+func TestLSHIndex(t *testing.T) {
+	documents := []string{
+		`Docker supports multi-stage builds, meaning docker will build in one container and you can copy the build artifacts to final image.`,
+		`Нет подключения к Интернету`,
+		`Docker supports multi-stage builds, meaning docker will build in one container and you can copy the build artifacts to the final image.`,
+		`Please consider chucking me a couple of quid for my time and effort.`,
+		`Docker supports multi-stage builds, meaning docker will build in one container and you can copy build artifacts to final image.`,
+		`Please consider chucking me a couple of quid for my time and effort!`,
+	}
+	m, err := NewMinHash(100, 2)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if _, err = NewLSHIndex(m, 30, 0.5); err != ErrInvalidMinHash {
+		t.Fatalf(`expected ErrInvalidMinHash`)
+	}
+	lsh, err := NewLSHIndex(m, 20, 0.5)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	index := NewMatrixIndex()
+	if err = index.Fit(documents...); err != nil {
+		t.Fatal(err)
+	}
+	lsh.FitMatrixIndex(index)
+
+	result := lsh.NearDuplicates(0, 0.5)
+	if len(result) != 2 || result[0] != 2 || result[1] != 4 {
+		t.Fatalf(`wrong near duplicates %v`, result)
+	}
+	if len(lsh.NearDuplicates(1, 0.5)) != 0 {
+		t.Fatalf(`document 1 has no duplicates`)
+	}
+
+	clusters := lsh.DuplicateClusters()
+	if len(clusters) != 2 {
+		t.Fatalf(`len not equals 2, %v`, clusters)
+	}
+	if len(clusters[0]) != 3 || clusters[0][0] != 0 || clusters[0][2] != 4 {
+		t.Fatalf(`wrong cluster %v`, clusters[0])
+	}
+	if len(clusters[1]) != 2 || clusters[1][0] != 3 || clusters[1][1] != 5 {
+		t.Fatalf(`wrong cluster %v`, clusters[1])
+	}
+}
@@ -0,0 +1,102 @@
+package word_index
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// parallelRange splits [0, n) into chunks processed by at most workers goroutines.
+// The first error returned by fn is returned.
+func parallelRange(n, workers int, fn func(low, high int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		if n == 0 {
+			return nil
+		}
+		return fn(0, n)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		err  error
+	)
+	chunk := (n + workers - 1) / workers
+	for low := 0; low < n; low += chunk {
+		high := low + chunk
+		if high > n {
+			high = n
+		}
+		wg.Add(1)
+		go func(low, high int) {
+			defer wg.Done()
+			if e := fn(low, high); e != nil {
+				once.Do(func() { err = e })
+			}
+		}(low, high)
+	}
+	wg.Wait()
+	return err
+}
+
+// SearchBatch runs SearchKNN of index for every query on workers goroutines,
+// result i corresponds to queries[i]. workers < 1 means GOMAXPROCS.
+// Queries not started before ctx is done are skipped and ctx.Err() is returned,
+// cancellation after all queries were started does not discard results.
+func SearchBatch(ctx context.Context, index VectorIndex, queries [][]float64, k, workers int) ([][]*Vector, error) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	result := make([][]*Vector, len(queries))
+	jobs := make(chan int)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				list, err := index.SearchKNN(queries[i], k)
+				if err != nil {
+					errs <- err
+					return
+				}
+				result[i] = list
+			}
+		}()
+	}
+
+	var err error
+loop:
+	for i := range queries {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		case err = <-errs:
+			break loop
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package word_index
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIndexVector_SearchBatch(t *testing.T) {
+	list := randomVectors(2000, 8, 11)
+	iv, err := NewIndexVector(WithWorkers(4))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = iv.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	queries := make([][]float64, 100)
+	for i := range queries {
+		queries[i] = list[i*7].V
+	}
+
+	result, err := iv.SearchBatch(context.Background(), queries, 3)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(result) != len(queries) {
+		t.Fatalf(`len not equals %d, %d`, len(queries), len(result))
+	}
+	for i, q := range queries {
+		want, _ := iv.SearchKNN(q, 3)
+		if len(result[i]) != 3 || result[i][0].Id != uint32(i*7) {
+			t.Fatalf(`result %d is not in input order`, i)
+		}
+		for j := range want {
+			if want[j].Id != result[i][j].Id {
+				t.Fatalf(`result %d differs from SearchKNN`, i)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = iv.SearchBatch(ctx, queries, 3); err != context.Canceled {
+		t.Fatalf(`expected context.Canceled, %v`, err)
+	}
+
+	if _, err = SearchBatch(context.Background(), iv, [][]float64{{1}}, 3, 2); err != ErrDimensionMismatch {
+		t.Fatalf(`expected ErrDimensionMismatch, %v`, err)
+	}
+	if _, err = NewIndexVector(WithWorkers(0)); err != ErrInvalidWorkers {
+		t.Fatalf(`expected ErrInvalidWorkers`)
+	}
+}
+
+// cancelIndex cancels context in the last expected SearchKNN call.
+type cancelIndex struct {
+	VectorIndex
+	calls  int32
+	last   int32
+	cancel context.CancelFunc
+}
+
+func (c *cancelIndex) SearchKNN(v []float64, k int) ([]*Vector, error) {
+	if atomic.AddInt32(&c.calls, 1) == c.last {
+		c.cancel()
+	}
+	return c.VectorIndex.SearchKNN(v, k)
+}
+
+func TestSearchBatch_CancelAfterLastQuery(t *testing.T) {
+	list := randomVectors(100, 4, 13)
+	iv, _ := NewIndexVector()
+	iv.Fit(list)
+	queries := [][]float64{list[0].V, list[1].V, list[2].V}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	index := &cancelIndex{VectorIndex: iv, last: int32(len(queries)), cancel: cancel}
+	result, err := SearchBatch(ctx, index, queries, 1, 1)
+	if err != nil {
+		t.Fatalf(`all queries finished, error must be nil: %v`, err)
+	}
+	if len(result) != len(queries) || result[2][0].Id != list[2].Id {
+		t.Fatalf(`wrong results after cancellation`)
+	}
+}
+
+func TestIndexVector_FitParallel(t *testing.T) {
+	list := randomVectors(1000, 3, 12)
+	sequential, _ := NewIndexVector(WithNeighborsThreshold(0.1), WithWorkers(1))
+	parallel, _ := NewIndexVector(WithNeighborsThreshold(0.1), WithWorkers(8))
+	if err := sequential.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err := parallel.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	for i, item := range sequential.itemsOrderZ {
+		if item.z != parallel.itemsOrderZ[i].z {
+			t.Fatalf(`order z differs at %d`, i)
+		}
+		a, _ := sequential.Neighbors(item.i.Id)
+		b, _ := parallel.Neighbors(item.i.Id)
+		if len(a) != len(b) {
+			t.Fatalf(`id %d: neighbors %d != %d`, item.i.Id, len(a), len(b))
+		}
+	}
+}
+
+func BenchmarkIndexVector_SearchBatch(b *testing.B) {
+	list := randomVectors(10000, 32, 1)
+	iv, _ := NewIndexVector()
+	iv.Fit(list)
+	queries := make([][]float64, 100)
+	for i := range queries {
+		queries[i] = list[i].V
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		iv.SearchBatch(context.Background(), queries, 10)
+	}
+}
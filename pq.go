@@ -0,0 +1,137 @@
+package word_index
+
+import (
+	"errors"
+	"math/rand"
+)
+
+var (
+	ErrQuantizerNotTrained = errors.New(`product quantizer is not trained`)
+	ErrInvalidQuantizer    = errors.New(`invalid product quantizer parameters`)
+)
+
+// ProductQuantizer splits vectors into m subspaces and encodes every subspace
+// by the index of the nearest of k centroids, one byte per subspace.
+type ProductQuantizer struct {
+	dim        int
+	m          int
+	k          int
+	iterations int
+	seed       int64
+	codebooks  [][][]float64
+}
+
+// NewProductQuantizer creates codec for vectors of size dim, dim must be divisible by m
+// and k must be in range [1, 256].
+func NewProductQuantizer(dim, m, k int) (*ProductQuantizer, error) {
+	if dim <= 0 || m <= 0 || dim%m != 0 || k <= 0 || k > 256 {
+		return nil, ErrInvalidQuantizer
+	}
+	return &ProductQuantizer{dim: dim, m: m, k: k, iterations: 25, seed: 1}, nil
+}
+
+// Train learns codebooks by k-means on every subspace.
+func (pq *ProductQuantizer) Train(vectors [][]float64) error {
+	if len(vectors) == 0 {
+		return ErrInvalidQuantizer
+	}
+	for _, v := range vectors {
+		if len(v) != pq.dim {
+			return ErrDimensionMismatch
+		}
+	}
+	rnd := rand.New(rand.NewSource(pq.seed))
+	sub := pq.dim / pq.m
+	codebooks := make([][][]float64, pq.m)
+	data := make([][]float64, len(vectors))
+	for s := 0; s < pq.m; s++ {
+		for i, v := range vectors {
+			data[i] = v[s*sub : (s+1)*sub]
+		}
+		codebooks[s] = kMeans(data, pq.k, pq.iterations, rnd)
+	}
+	pq.codebooks = codebooks
+	return nil
+}
+
+func (pq *ProductQuantizer) Trained() bool {
+	return pq.codebooks != nil
+}
+
+// Encode returns code of v, one centroid index per subspace.
+func (pq *ProductQuantizer) Encode(v []float64) ([]byte, error) {
+	if !pq.Trained() {
+		return nil, ErrQuantizerNotTrained
+	}
+	if len(v) != pq.dim {
+		return nil, ErrDimensionMismatch
+	}
+	sub := pq.dim / pq.m
+	code := make([]byte, pq.m)
+	for s := 0; s < pq.m; s++ {
+		c, _ := nearestCentroid(pq.codebooks[s], v[s*sub:(s+1)*sub])
+		code[s] = byte(c)
+	}
+	return code, nil
+}
+
+// clone returns a copy of the quantizer not sharing codebooks with pq.
+func (pq *ProductQuantizer) clone() *ProductQuantizer {
+	next := *pq
+	if !pq.Trained() {
+		return &next
+	}
+	next.codebooks = make([][][]float64, len(pq.codebooks))
+	for s, codebook := range pq.codebooks {
+		next.codebooks[s] = make([][]float64, len(codebook))
+		for c, centroid := range codebook {
+			next.codebooks[s][c] = append([]float64{}, centroid...)
+		}
+	}
+	return &next
+}
+
+// Decode reconstructs approximate vector from code.
+func (pq *ProductQuantizer) Decode(code []byte) []float64 {
+	return pq.decodeTo(make([]float64, 0, pq.dim), code)
+}
+
+// decodeTo reconstructs vector from code into dst reusing its capacity.
+func (pq *ProductQuantizer) decodeTo(dst []float64, code []byte) []float64 {
+	sub := pq.dim / pq.m
+	dst = dst[:0]
+	for s, c := range code {
+		dst = append(dst, pq.codebooks[s][c][:sub]...)
+	}
+	return dst
+}
+
+// DistanceTable precomputes squared distances from subvectors of query q to every centroid,
+// it is used for asymmetric distance computation of many codes.
+func (pq *ProductQuantizer) DistanceTable(q []float64) ([][]float64, error) {
+	if !pq.Trained() {
+		return nil, ErrQuantizerNotTrained
+	}
+	if len(q) != pq.dim {
+		return nil, ErrDimensionMismatch
+	}
+	sub := pq.dim / pq.m
+	table := make([][]float64, pq.m)
+	for s := 0; s < pq.m; s++ {
+		table[s] = make([]float64, len(pq.codebooks[s]))
+		for c, centroid := range pq.codebooks[s] {
+			table[s][c] = distEuclideanSquare(q[s*sub:(s+1)*sub], centroid)
+		}
+	}
+	return table, nil
+}
+
+// AsymmetricDistance returns approximate squared Euclidean distance between the query
+// of table and the encoded vector.
+func AsymmetricDistance(table [][]float64, code []byte) float64 {
+	d := float64(0)
+	for s, c := range code {
+		d += table[s][c]
+	}
+	return d
+}
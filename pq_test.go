@@ -0,0 +1,219 @@
+package word_index
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestProductQuantizer(t *testing.T) {
+	if _, err := NewProductQuantizer(10, 3, 16); err != ErrInvalidQuantizer {
+		t.Fatalf(`expected ErrInvalidQuantizer`)
+	}
+	pq, err := NewProductQuantizer(8, 4, 16)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if _, err = pq.Encode(make([]float64, 8)); err != ErrQuantizerNotTrained {
+		t.Fatalf(`expected ErrQuantizerNotTrained`)
+	}
+
+	rnd := rand.New(rand.NewSource(5))
+	vectors := make([][]float64, 500)
+	for i := range vectors {
+		vectors[i] = make([]float64, 8)
+		for j := range vectors[i] {
+			vectors[i][j] = rnd.Float64()
+		}
+	}
+	if err = pq.Train(vectors); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+
+	errSum := float64(0)
+	for _, v := range vectors {
+		code, err := pq.Encode(v)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		if len(code) != 4 {
+			t.Fatalf(`len not equals 4, %d`, len(code))
+		}
+		table, err := pq.DistanceTable(v)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		d := AsymmetricDistance(table, code)
+		if r := distEuclideanSquare(v, pq.Decode(code)); r-d > 1e-9 || d-r > 1e-9 {
+			t.Fatalf(`asymmetric distance %f != reconstruction error %f`, d, r)
+		}
+		errSum += d
+	}
+	// uniform random noise has mean squared norm 8/12
+	if errSum/float64(len(vectors)) > 0.2 {
+		t.Fatalf(`quantization error too large: %f`, errSum/float64(len(vectors)))
+	}
+}
+
+func TestIndexVector_SearchKNN(t *testing.T) {
+	rnd := rand.New(rand.NewSource(6))
+	list := make([]*Vector, 1000)
+	for i := range list {
+		v := make([]float64, 8)
+		for j := range v {
+			v[j] = rnd.Float64()
+		}
+		list[i] = NewVector(uint32(i), v, i)
+	}
+
+	exact, err := NewIndexVector()
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = exact.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	pq, _ := NewProductQuantizer(8, 4, 32)
+	rerank, err := NewIndexVector(WithProductQuantizer(pq, 10))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = rerank.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	compact, err := NewIndexVector(WithProductQuantizer(pq, 0))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = compact.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+
+	const k = 10
+	hitsRerank, hitsCompact := 0, 0
+	for n := 0; n < 20; n++ {
+		q := list[n].V
+		want, err := exact.SearchKNN(q, k)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		if len(want) != k || want[0].Id != uint32(n) {
+			t.Fatalf(`exact knn must start with query vector %d`, n)
+		}
+		for i := 1; i < len(want); i++ {
+			if distEuclidean(want[i-1].V, q) > distEuclidean(want[i].V, q) {
+				t.Fatalf(`knn result is not ordered`)
+			}
+		}
+		ids := make(map[uint32]struct{})
+		for _, v := range want {
+			ids[v.Id] = struct{}{}
+		}
+
+		got, err := rerank.SearchKNN(q, k)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		for _, v := range got {
+			if _, ok := ids[v.Id]; ok {
+				hitsRerank++
+			}
+		}
+		got, err = compact.SearchKNN(q, k)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		for _, v := range got {
+			if v.V != nil || v.Data.(int) != int(v.Id) {
+				t.Fatalf(`compact index must keep only id and data`)
+			}
+			if _, ok := ids[v.Id]; ok {
+				hitsCompact++
+			}
+		}
+	}
+	if hitsRerank < hitsCompact || hitsRerank < 20*k*8/10 {
+		t.Fatalf(`low recall: rerank %d, compact %d`, hitsRerank, hitsCompact)
+	}
+
+	for _, iv := range []*IndexVector{exact, rerank, compact} {
+		got, err := iv.SearchKNN(list[0].V, math.MaxInt64/2)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		if len(got) != len(list) {
+			t.Fatalf(`huge k must return all %d vectors, %d`, len(list), len(got))
+		}
+	}
+}
+
+func TestIndexVector_CompactNeighbors(t *testing.T) {
+	rnd := rand.New(rand.NewSource(12))
+	list := make([]*Vector, 300)
+	for i := range list {
+		list[i] = NewVector(uint32(i), []float64{rnd.Float64() * 10, rnd.Float64() * 10}, nil)
+	}
+	pq, _ := NewProductQuantizer(2, 2, 16)
+	fitted, err := NewIndexVector(WithProductQuantizer(pq, 0), WithNeighborsThreshold(1))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = fitted.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	added, _ := NewIndexVector(WithProductQuantizer(pq, 0), WithNeighborsThreshold(1))
+	if err = added.Fit(list[:1]); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	for _, v := range list[1:] {
+		if err = added.Add(NewVector(v.Id, append([]float64{}, v.V...), nil)); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+	}
+
+	for _, v := range list {
+		a, _ := fitted.Neighbors(v.Id)
+		b, _ := added.Neighbors(v.Id)
+		if len(a) != len(b) {
+			t.Fatalf(`id %d: neighbors of Fit %d != neighbors of Add %d`, v.Id, len(a), len(b))
+		}
+		for i := range a {
+			if a[i].Id != b[i].Id {
+				t.Fatalf(`id %d: different neighbors`, v.Id)
+			}
+		}
+	}
+}
+
+func TestIndexVector_UpdateKeepsVector(t *testing.T) {
+	rnd := rand.New(rand.NewSource(13))
+	list := make([]*Vector, 100)
+	for i := range list {
+		list[i] = NewVector(uint32(i), []float64{rnd.Float64(), rnd.Float64()}, nil)
+	}
+	pq, _ := NewProductQuantizer(2, 2, 16)
+	iv, err := NewIndexVector(WithProductQuantizer(pq, 0), WithNeighborsThreshold(0.1))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = iv.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	before, _ := iv.Neighbors(1)
+	if err = iv.Update(NewVector(1, []float64{1, 2, 3}, nil)); err != ErrDimensionMismatch {
+		t.Fatalf(`expected ErrDimensionMismatch, %v`, err)
+	}
+	after, err := iv.Neighbors(1)
+	if err != nil {
+		t.Fatalf(`vector must be kept after failed update: %s`, err.Error())
+	}
+	if len(before) != len(after) || len(iv.itemsOrderZ) != len(list) {
+		t.Fatalf(`index changed by failed update`)
+	}
+	if err = iv.Update(NewVector(1, []float64{0.5, 0.5}, nil)); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = iv.Update(NewVector(1000, []float64{0.5, 0.5}, nil)); err != ErrVectorNotFound {
+		t.Fatalf(`expected ErrVectorNotFound`)
+	}
+}
@@ -0,0 +1,80 @@
+package word_index
+
+// SearchOptions selects a page of hits. Limit 0 means all hits after Offset.
+type SearchOptions struct {
+	Offset int
+	Limit  int
+	// TotalCap stops counting hits after TotalCap hits once the page is filled and
+	// estimates the total, 0 means exact count. It never shortens the page.
+	TotalCap int
+}
+
+// SearchResult is a page of document ids with the total number of hits.
+type SearchResult struct {
+	Hits []int
+	// Total is the exact number of hits if TotalExact, otherwise an estimate
+	// extrapolated from the share of matched documents among scanned ones.
+	Total      int
+	TotalExact bool
+}
+
+// IndexSearcher is implemented by indexes returning pages of matched documents.
+type IndexSearcher interface {
+	Index
+	Search(string, SearchOptions) SearchResult
+}
+
+// searchCursor collects the page and counts hits of c, size is the number of documents.
+func searchCursor(c Cursor, opts SearchOptions, size int) SearchResult {
+	defer c.Close()
+	result := SearchResult{Hits: make([]int, 0), TotalExact: true}
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	end := opts.Offset + opts.Limit
+
+	count, last := 0, emptyFind
+	for {
+		id, ok := c.Next()
+		if !ok {
+			break
+		}
+		if count >= opts.Offset && (opts.Limit <= 0 || count < end) {
+			result.Hits = append(result.Hits, id)
+		}
+		count++
+		last = id
+		if opts.TotalCap > 0 && count >= opts.TotalCap && opts.Limit > 0 && count >= end {
+			if _, ok := c.Next(); ok {
+				result.TotalExact = false
+			}
+			break
+		}
+	}
+
+	result.Total = count
+	if !result.TotalExact && last >= 0 {
+		if estimate := count * size / (last + 1); estimate > count {
+			result.Total = estimate
+		}
+	}
+	return result
+}
+
+// Search returns a page of documents matching query in document order.
+func (i *indexWord) Search(query string, opts SearchOptions) SearchResult {
+	return searchCursor(i.Cursor(query), opts, len(i.data))
+}
+
+func (i *indexWordSync) Search(query string, opts SearchOptions) SearchResult {
+	return i.snapshot().Search(query, opts)
+}
+
+// Search returns a page of documents matching any word of query in document order.
+func (m *MatrixIndex) Search(query string, opts SearchOptions) SearchResult {
+	return searchCursor(m.Cursor(query), opts, len(m.documents))
+}
+
+func (m *MatrixIndexSync) Search(query string, opts SearchOptions) SearchResult {
+	return m.snapshot().Search(query, opts)
+}
@@ -0,0 +1,70 @@
+package word_index
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	docs := make([]string, 1000)
+	for i := range docs {
+		docs[i] = fmt.Sprintf(`doc %d`, i)
+		if i%2 == 0 {
+			docs[i] += ` even`
+		}
+	}
+	for name, index := range map[string]Index{
+		`matrix`:      NewMatrixIndex(),
+		`matrix sync`: NewMatrixIndexSync(),
+		`bin`:         NewIndex(),
+		`bin sync`:    NewIndexSync(),
+		`sharded`:     NewIndexSharded(4),
+	} {
+		index.Add(docs...)
+		searcher, ok := index.(IndexSearcher)
+		if !ok {
+			t.Fatalf(`%s: must implement IndexSearcher`, name)
+		}
+		search := searcher.Search
+		result := search(`even`, SearchOptions{Offset: 10, Limit: 5})
+		if !result.TotalExact || result.Total != 500 {
+			t.Fatalf(`%s: total not equals 500, %d`, name, result.Total)
+		}
+		if len(result.Hits) != 5 || result.Hits[0] != 20 || result.Hits[4] != 28 {
+			t.Fatalf(`%s: wrong page %v`, name, result.Hits)
+		}
+
+		result = search(`even`, SearchOptions{Offset: 495, Limit: 10})
+		if len(result.Hits) != 5 || result.Total != 500 {
+			t.Fatalf(`%s: wrong last page %v`, name, result.Hits)
+		}
+
+		result = search(`even`, SearchOptions{Limit: 10, TotalCap: 100})
+		if result.TotalExact {
+			t.Fatalf(`%s: total must be estimated`, name)
+		}
+		if len(result.Hits) != 10 || result.Total < 400 || result.Total > 600 {
+			t.Fatalf(`%s: wrong estimate %d`, name, result.Total)
+		}
+
+		result = search(`unknown`, SearchOptions{Limit: 10, TotalCap: 100})
+		if !result.TotalExact || result.Total != 0 || len(result.Hits) != 0 {
+			t.Fatalf(`%s: wrong empty result`, name)
+		}
+
+		result = search(`even`, SearchOptions{Offset: 490})
+		if len(result.Hits) != 10 {
+			t.Fatalf(`%s: zero limit must return all hits after offset`, name)
+		}
+
+		result = search(`even`, SearchOptions{TotalCap: 100})
+		if len(result.Hits) != 500 || result.Total != 500 || !result.TotalExact {
+			t.Fatalf(`%s: total cap must not shorten the page, %d hits`, name, len(result.Hits))
+		}
+
+		result = search(`even`, SearchOptions{Offset: 150, Limit: 10, TotalCap: 100})
+		if len(result.Hits) != 10 || result.Hits[0] != 300 || result.TotalExact {
+			t.Fatalf(`%s: page after total cap must be filled %v`, name, result.Hits)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package word_index
+
+import (
+	"math"
+)
+
+// DotFloat32 returns dot product of a and b, 0 if sizes are different.
+func DotFloat32(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	return dotFloat32(a, b)
+}
+
+// L2SquareFloat32 returns squared Euclidean distance between a and b, 0 if sizes are different.
+func L2SquareFloat32(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	return l2SquareFloat32(a, b)
+}
+
+func dotFloat32Generic(a, b []float32) float32 {
+	var s0, s1, s2, s3 float32
+	n := len(a) &^ 3
+	for i := 0; i < n; i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for i := n; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+func l2SquareFloat32Generic(a, b []float32) float32 {
+	var s0, s1, s2, s3 float32
+	n := len(a) &^ 3
+	for i := 0; i < n; i += 4 {
+		d0 := a[i] - b[i]
+		d1 := a[i+1] - b[i+1]
+		d2 := a[i+2] - b[i+2]
+		d3 := a[i+3] - b[i+3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for i := n; i < len(a); i++ {
+		d := a[i] - b[i]
+		s0 += d * d
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// IndexFlat32 keeps all vectors as float32 in one contiguous slice and searches
+// by exact scan with SIMD kernels. Only MetricEuclidean and MetricCosine are supported.
+type IndexFlat32 struct {
+	dim    int
+	cosine bool
+	data   []float32
+	norms  []float32
+	items  []*Vector
+	rows   map[uint32]int
+}
+
+func (f *IndexFlat32) Fit(list []*Vector) error {
+	f.dim = 0
+	f.data = make([]float32, 0)
+	f.norms = make([]float32, 0)
+	f.items = make([]*Vector, 0, len(list))
+	f.rows = make(map[uint32]int)
+	for _, v := range list {
+		if err := f.Add(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *IndexFlat32) Add(v *Vector) error {
+	if f.rows == nil {
+		f.rows = make(map[uint32]int)
+	}
+	if _, ok := f.rows[v.Id]; ok {
+		return ErrVectorExists
+	}
+	if len(f.items) == 0 {
+		f.dim = len(v.V)
+	}
+	if len(v.V) != f.dim {
+		return ErrDimensionMismatch
+	}
+	row := toFloat32(v.V)
+	f.data = append(f.data, row...)
+	f.norms = append(f.norms, float32(math.Sqrt(float64(dotFloat32(row, row)))))
+	f.rows[v.Id] = len(f.items)
+	f.items = append(f.items, &Vector{Id: v.Id, Data: v.Data, Attrs: v.Attrs})
+	return nil
+}
+
+// Remove moves the last row in place of the removed one.
+func (f *IndexFlat32) Remove(id uint32) error {
+	row, ok := f.rows[id]
+	if !ok {
+		return ErrVectorNotFound
+	}
+	last := len(f.items) - 1
+	if row != last {
+		copy(f.row(row), f.row(last))
+		f.norms[row] = f.norms[last]
+		f.items[row] = f.items[last]
+		f.rows[f.items[row].Id] = row
+	}
+	f.data = f.data[:last*f.dim]
+	f.norms = f.norms[:last]
+	f.items = f.items[:last]
+	delete(f.rows, id)
+	return nil
+}
+
+// Update replaces the vector with the same id, the old vector is kept when v can not be added.
+func (f *IndexFlat32) Update(v *Vector) error {
+	if _, ok := f.rows[v.Id]; !ok {
+		return ErrVectorNotFound
+	}
+	if len(v.V) != f.dim {
+		return ErrDimensionMismatch
+	}
+	if err := f.Remove(v.Id); err != nil {
+		return err
+	}
+	return f.Add(v)
+}
+
+func (f *IndexFlat32) row(i int) []float32 {
+	return f.data[i*f.dim : (i+1)*f.dim]
+}
+
+// SearchKNN returns k nearest vectors, closest first. Returned vectors contain
+// coordinates converted back to float64.
+func (f *IndexFlat32) SearchKNN(v []float64, k int) ([]*Vector, error) {
+	if k <= 0 || len(f.items) == 0 {
+		return []*Vector{}, nil
+	}
+	if len(v) != f.dim {
+		return nil, ErrDimensionMismatch
+	}
+	q := toFloat32(v)
+	qNorm := float32(math.Sqrt(float64(dotFloat32(q, q))))
+
+	h := newCandidateHeap(k, len(f.items))
+	for i := range f.items {
+		var dist float64
+		if f.cosine {
+			if f.norms[i] == 0 || qNorm == 0 {
+				dist = 1
+			} else {
+				dist = float64(1 - dotFloat32(f.row(i), q)/(f.norms[i]*qNorm))
+			}
+		} else {
+			dist = float64(l2SquareFloat32(f.row(i), q))
+		}
+		h.push(vectorCandidate{row: i, dist: dist}, k)
+	}
+	candidates := h.sorted()
+	result := make([]*Vector, len(candidates))
+	for i, c := range candidates {
+		item := f.items[c.row]
+		result[i] = &Vector{Id: item.Id, V: toFloat64(f.row(c.row)), Data: item.Data, Attrs: item.Attrs}
+	}
+	return result, nil
+}
+
+func NewIndexFlat32(metric Metric) (*IndexFlat32, error) {
+	switch metric {
+	case MetricEuclidean:
+		return &IndexFlat32{}, nil
+	case MetricCosine:
+		return &IndexFlat32{cosine: true}, nil
+	}
+	return nil, ErrInvalidMetric
+}
+
+func toFloat32(v []float64) []float32 {
+	result := make([]float32, len(v))
+	for i, x := range v {
+		result[i] = float32(x)
+	}
+	return result
+}
+
+func toFloat64(v []float32) []float64 {
+	result := make([]float64, len(v))
+	for i, x := range v {
+		result[i] = float64(x)
+	}
+	return result
+}
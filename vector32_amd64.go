@@ -0,0 +1,13 @@
+//go:build amd64
+// +build amd64
+
+package word_index
+
+// dotFloat32 and l2SquareFloat32 are implemented with SSE in vector32_amd64.s,
+// len(b) must be not less than len(a).
+
+//go:noescape
+func dotFloat32(a, b []float32) float32
+
+//go:noescape
+func l2SquareFloat32(a, b []float32) float32
@@ -0,0 +1,126 @@
+//go:build amd64
+// +build amd64
+
+#include "textflag.h"
+
+// func dotFloat32(a, b []float32) float32
+TEXT ·dotFloat32(SB), NOSPLIT, $0-52
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	XORPS X0, X0
+	XORPS X1, X1
+	MOVQ CX, DX
+	SHRQ $3, DX
+	JZ   dot_block4
+
+dot_loop8:
+	MOVUPS (SI), X2
+	MOVUPS 16(SI), X3
+	MOVUPS (DI), X4
+	MOVUPS 16(DI), X5
+	MULPS  X4, X2
+	MULPS  X5, X3
+	ADDPS  X2, X0
+	ADDPS  X3, X1
+	ADDQ   $32, SI
+	ADDQ   $32, DI
+	DECQ   DX
+	JNZ    dot_loop8
+
+dot_block4:
+	ADDPS X1, X0
+	TESTQ $4, CX
+	JZ    dot_reduce
+	MOVUPS (SI), X2
+	MOVUPS (DI), X4
+	MULPS  X4, X2
+	ADDPS  X2, X0
+	ADDQ   $16, SI
+	ADDQ   $16, DI
+
+dot_reduce:
+	MOVAPS X0, X1
+	SHUFPS $0x4E, X1, X1
+	ADDPS  X1, X0
+	MOVAPS X0, X1
+	SHUFPS $0xB1, X1, X1
+	ADDPS  X1, X0
+	ANDQ   $3, CX
+	JZ     dot_done
+
+dot_tail:
+	MOVSS (SI), X2
+	MULSS (DI), X2
+	ADDSS X2, X0
+	ADDQ  $4, SI
+	ADDQ  $4, DI
+	DECQ  CX
+	JNZ   dot_tail
+
+dot_done:
+	MOVSS X0, ret+48(FP)
+	RET
+
+// func l2SquareFloat32(a, b []float32) float32
+TEXT ·l2SquareFloat32(SB), NOSPLIT, $0-52
+	MOVQ a_base+0(FP), SI
+	MOVQ a_len+8(FP), CX
+	MOVQ b_base+24(FP), DI
+	XORPS X0, X0
+	XORPS X1, X1
+	MOVQ CX, DX
+	SHRQ $3, DX
+	JZ   l2_block4
+
+l2_loop8:
+	MOVUPS (SI), X2
+	MOVUPS 16(SI), X3
+	MOVUPS (DI), X4
+	MOVUPS 16(DI), X5
+	SUBPS  X4, X2
+	SUBPS  X5, X3
+	MULPS  X2, X2
+	MULPS  X3, X3
+	ADDPS  X2, X0
+	ADDPS  X3, X1
+	ADDQ   $32, SI
+	ADDQ   $32, DI
+	DECQ   DX
+	JNZ    l2_loop8
+
+l2_block4:
+	ADDPS X1, X0
+	TESTQ $4, CX
+	JZ    l2_reduce
+	MOVUPS (SI), X2
+	MOVUPS (DI), X4
+	SUBPS  X4, X2
+	MULPS  X2, X2
+	ADDPS  X2, X0
+	ADDQ   $16, SI
+	ADDQ   $16, DI
+
+l2_reduce:
+	MOVAPS X0, X1
+	SHUFPS $0x4E, X1, X1
+	ADDPS  X1, X0
+	MOVAPS X0, X1
+	SHUFPS $0xB1, X1, X1
+	ADDPS  X1, X0
+	ANDQ   $3, CX
+	JZ     l2_done
+
+l2_tail:
+	MOVSS (SI), X2
+	SUBSS (DI), X2
+	MULSS X2, X2
+	ADDSS X2, X0
+	ADDQ  $4, SI
+	ADDQ  $4, DI
+	DECQ  CX
+	JNZ   l2_tail
+
+l2_done:
+	MOVSS X0, ret+48(FP)
+	RET
@@ -0,0 +1,12 @@
+//go:build !amd64
+// +build !amd64
+
+package word_index
+
+func dotFloat32(a, b []float32) float32 {
+	return dotFloat32Generic(a, b)
+}
+
+func l2SquareFloat32(a, b []float32) float32 {
+	return l2SquareFloat32Generic(a, b)
+}
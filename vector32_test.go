@@ -0,0 +1,183 @@
+package word_index
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFloat32Kernels(t *testing.T) {
+	rnd := rand.New(rand.NewSource(9))
+	for _, n := range []int{0, 1, 3, 4, 7, 8, 9, 16, 31, 384} {
+		a := make([]float32, n)
+		b := make([]float32, n)
+		dot, l2 := float64(0), float64(0)
+		for i := range a {
+			a[i], b[i] = rnd.Float32()*2-1, rnd.Float32()*2-1
+			dot += float64(a[i]) * float64(b[i])
+			l2 += float64(a[i]-b[i]) * float64(a[i]-b[i])
+		}
+		for name, got := range map[string]float32{
+			`dot`:         DotFloat32(a, b),
+			`dot generic`: dotFloat32Generic(a, b),
+			`l2`:          L2SquareFloat32(a, b),
+			`l2 generic`:  l2SquareFloat32Generic(a, b),
+		} {
+			want := dot
+			if name[0] == 'l' {
+				want = l2
+			}
+			if math.Abs(float64(got)-want) > 1e-3 {
+				t.Fatalf(`n %d, %s: %f != %f`, n, name, got, want)
+			}
+		}
+	}
+	if DotFloat32([]float32{1}, []float32{1, 2}) != 0 {
+		t.Fatalf(`different sizes must give 0`)
+	}
+}
+
+func TestIndexFlat32_SearchKNN(t *testing.T) {
+	if _, err := NewIndexFlat32(nil); err != ErrInvalidMetric {
+		t.Fatalf(`expected ErrInvalidMetric`)
+	}
+	list := randomVectors(500, 13, 10)
+	for _, metric := range []Metric{MetricEuclidean, MetricCosine} {
+		exact, _ := NewIndexVector(WithMetric(metric))
+		flat, err := NewIndexFlat32(metric)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		var _ VectorIndex = flat
+		if err = exact.Fit(list); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		if err = flat.Fit(list); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		for n := 0; n < 10; n++ {
+			want, _ := exact.SearchKNN(list[n].V, 5)
+			got, err := flat.SearchKNN(list[n].V, 5)
+			if err != nil {
+				t.Fatalf(`%s`, err.Error())
+			}
+			for i := range want {
+				if want[i].Id != got[i].Id {
+					t.Fatalf(`id not equals %d, %d`, want[i].Id, got[i].Id)
+				}
+			}
+		}
+	}
+
+	flat, _ := NewIndexFlat32(MetricEuclidean)
+	if err := flat.Fit(list[:3]); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err := flat.Remove(list[0].Id); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	got, _ := flat.SearchKNN(list[2].V, 1)
+	if len(got) != 1 || got[0].Id != list[2].Id || math.Abs(got[0].V[0]-list[2].V[0]) > 1e-6 {
+		t.Fatalf(`moved row is broken`)
+	}
+	if err := flat.Add(NewVector(100, []float64{1}, nil)); err != ErrDimensionMismatch {
+		t.Fatalf(`expected ErrDimensionMismatch`)
+	}
+	if err := flat.Update(NewVector(list[2].Id, []float64{1}, nil)); err != ErrDimensionMismatch {
+		t.Fatalf(`expected ErrDimensionMismatch`)
+	}
+	if _, ok := flat.rows[list[2].Id]; !ok {
+		t.Fatalf(`vector must be kept after failed update`)
+	}
+	if got, _ = flat.SearchKNN(list[2].V, 1<<40); len(got) != 2 {
+		t.Fatalf(`huge k must return all vectors, %d`, len(got))
+	}
+}
+
+func randomVectors(n, dim int, seed int64) []*Vector {
+	rnd := rand.New(rand.NewSource(seed))
+	list := make([]*Vector, n)
+	for i := range list {
+		v := make([]float64, dim)
+		for j := range v {
+			v[j] = rnd.Float64()
+		}
+		list[i] = NewVector(uint32(i), v, nil)
+	}
+	return list
+}
+
+func benchmarkVectors32(dim int) ([]float32, []float32) {
+	rnd := rand.New(rand.NewSource(1))
+	a, b := make([]float32, dim), make([]float32, dim)
+	for i := range a {
+		a[i], b[i] = rnd.Float32(), rnd.Float32()
+	}
+	return a, b
+}
+
+func BenchmarkDistEuclideanFloat64(b *testing.B) {
+	a, c := benchmarkVectors32(384)
+	x, y := toFloat64(a), toFloat64(c)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		distEuclidean(x, y)
+	}
+}
+
+func BenchmarkDistEuclideanPow(b *testing.B) {
+	a, c := benchmarkVectors32(384)
+	x, y := toFloat64(a), toFloat64(c)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := float64(0)
+		for j := 0; j < len(x); j++ {
+			s += math.Pow(x[j]-y[j], 2)
+		}
+		math.Sqrt(s)
+	}
+}
+
+func BenchmarkL2SquareFloat32Generic(b *testing.B) {
+	x, y := benchmarkVectors32(384)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l2SquareFloat32Generic(x, y)
+	}
+}
+
+func BenchmarkL2SquareFloat32(b *testing.B) {
+	x, y := benchmarkVectors32(384)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		L2SquareFloat32(x, y)
+	}
+}
+
+func BenchmarkDotFloat32(b *testing.B) {
+	x, y := benchmarkVectors32(384)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		DotFloat32(x, y)
+	}
+}
+
+func BenchmarkIndexVector_SearchKNN(b *testing.B) {
+	list := randomVectors(10000, 128, 1)
+	iv, _ := NewIndexVector()
+	iv.Fit(list)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		iv.SearchKNN(list[i%len(list)].V, 10)
+	}
+}
+
+func BenchmarkIndexFlat32_SearchKNN(b *testing.B) {
+	list := randomVectors(10000, 128, 1)
+	flat, _ := NewIndexFlat32(MetricEuclidean)
+	flat.Fit(list)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		flat.SearchKNN(list[i%len(list)].V, 10)
+	}
+}
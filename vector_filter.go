@@ -0,0 +1,566 @@
+package word_index
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrFilterSyntax = errors.New(`filter syntax error`)
+)
+
+// preFilterRatio is the maximum share of vectors selected by a filter
+// when search scans only filter candidates instead of filtering search results.
+const preFilterRatio = 0.1
+
+// Attributes are structured fields of a vector used by filters.
+type Attributes struct {
+	Tags    map[string]string
+	Numbers map[string]float64
+}
+
+func (v *Vector) SetTag(key, value string) *Vector {
+	if v.Attrs.Tags == nil {
+		v.Attrs.Tags = make(map[string]string)
+	}
+	v.Attrs.Tags[key] = value
+	return v
+}
+
+func (v *Vector) SetNumber(key string, value float64) *Vector {
+	if v.Attrs.Numbers == nil {
+		v.Attrs.Numbers = make(map[string]float64)
+	}
+	v.Attrs.Numbers[key] = value
+	return v
+}
+
+// Filter restricts vector search results.
+type Filter interface {
+	Match(*Vector) bool
+}
+
+// FilterFunc is an arbitrary predicate, it is never used for pre-filtering.
+type FilterFunc func(*Vector) bool
+
+func (f FilterFunc) Match(v *Vector) bool {
+	return f(v)
+}
+
+type itemSet map[*indexVectorItem]struct{}
+
+// candidateFilter is implemented by filters able to select matching items from attributeIndex.
+// The returned set may contain items not matching the filter but never misses matching ones.
+type candidateFilter interface {
+	candidates(*attributeIndex) (itemSet, bool)
+}
+
+type filterCompare struct {
+	key   string
+	op    string
+	value string
+	num   float64
+	isNum bool
+}
+
+// FilterCompare compares attribute key with value, op is one of = != > >= < <=.
+// Numeric values are compared with Attributes.Numbers, others with Attributes.Tags.
+func FilterCompare(key, op, value string) (Filter, error) {
+	switch op {
+	case `=`, `!=`, `>`, `>=`, `<`, `<=`:
+	default:
+		return nil, ErrFilterSyntax
+	}
+	f := &filterCompare{key: key, op: op, value: value}
+	if num, err := strconv.ParseFloat(value, 64); err == nil {
+		f.num, f.isNum = num, true
+	}
+	return f, nil
+}
+
+func (f *filterCompare) Match(v *Vector) bool {
+	if f.op == `!=` {
+		return !f.equal(v)
+	}
+	if f.op == `=` {
+		return f.equal(v)
+	}
+	var c int
+	if f.isNum {
+		n, ok := v.Attrs.Numbers[f.key]
+		if !ok {
+			return false
+		}
+		if n < f.num {
+			c = -1
+		} else if n > f.num {
+			c = 1
+		}
+	} else {
+		t, ok := v.Attrs.Tags[f.key]
+		if !ok {
+			return false
+		}
+		c = strings.Compare(t, f.value)
+	}
+	switch f.op {
+	case `>`:
+		return c > 0
+	case `>=`:
+		return c >= 0
+	case `<`:
+		return c < 0
+	}
+	return c <= 0
+}
+
+func (f *filterCompare) equal(v *Vector) bool {
+	if t, ok := v.Attrs.Tags[f.key]; ok && t == f.value {
+		return true
+	}
+	if n, ok := v.Attrs.Numbers[f.key]; ok && f.isNum {
+		return n == f.num
+	}
+	return false
+}
+
+func (f *filterCompare) candidates(a *attributeIndex) (itemSet, bool) {
+	if f.op == `!=` || (f.op != `=` && !f.isNum) {
+		return nil, false
+	}
+	set := make(itemSet)
+	if f.op == `=` {
+		for item := range a.tags[attributeTagKey(f.key, f.value)] {
+			set[item] = struct{}{}
+		}
+		if !f.isNum {
+			return set, true
+		}
+	}
+	postings := a.numbers[f.key]
+	low := sort.Search(len(postings), func(i int) bool {
+		if f.op == `>` {
+			return postings[i].value > f.num
+		}
+		return postings[i].value >= f.num
+	})
+	high := len(postings)
+	switch f.op {
+	case `=`, `<=`:
+		high = sort.Search(len(postings), func(i int) bool { return postings[i].value > f.num })
+	case `<`:
+		high = sort.Search(len(postings), func(i int) bool { return postings[i].value >= f.num })
+	}
+	if f.op == `<` || f.op == `<=` {
+		low = 0
+	}
+	for i := low; i < high; i++ {
+		set[postings[i].item] = struct{}{}
+	}
+	return set, true
+}
+
+type filterAnd []Filter
+
+func FilterAnd(filters ...Filter) Filter {
+	return filterAnd(filters)
+}
+
+func (f filterAnd) Match(v *Vector) bool {
+	for _, filter := range f {
+		if !filter.Match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f filterAnd) candidates(a *attributeIndex) (itemSet, bool) {
+	var result itemSet
+	for _, filter := range f {
+		cf, ok := filter.(candidateFilter)
+		if !ok {
+			continue
+		}
+		set, ok := cf.candidates(a)
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = set
+			continue
+		}
+		if len(set) < len(result) {
+			result, set = set, result
+		}
+		for item := range result {
+			if _, ok := set[item]; !ok {
+				delete(result, item)
+			}
+		}
+	}
+	return result, result != nil
+}
+
+type filterOr []Filter
+
+func FilterOr(filters ...Filter) Filter {
+	return filterOr(filters)
+}
+
+func (f filterOr) Match(v *Vector) bool {
+	for _, filter := range f {
+		if filter.Match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f filterOr) candidates(a *attributeIndex) (itemSet, bool) {
+	result := make(itemSet)
+	for _, filter := range f {
+		cf, ok := filter.(candidateFilter)
+		if !ok {
+			return nil, false
+		}
+		set, ok := cf.candidates(a)
+		if !ok {
+			return nil, false
+		}
+		for item := range set {
+			result[item] = struct{}{}
+		}
+	}
+	return result, true
+}
+
+type filterNot struct {
+	f Filter
+}
+
+func FilterNot(f Filter) Filter {
+	return filterNot{f: f}
+}
+
+func (f filterNot) Match(v *Vector) bool {
+	return !f.f.Match(v)
+}
+
+// ParseFilter parses expression like `lang=ru AND (year>=2020 OR NOT draft=1)`.
+// Values containing spaces or operators are written in double quotes.
+func ParseFilter(expr string) (Filter, error) {
+	p := &filterParser{tokens: tokenizeFilter(expr)}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, ErrFilterSyntax
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *filterParser) next() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ``
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	f, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	list := filterOr{f}
+	for strings.ToUpper(p.next()) == `OR` {
+		p.pos++
+		f, err = p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	if len(list) == 1 {
+		return list[0], nil
+	}
+	return list, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	f, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	list := filterAnd{f}
+	for strings.ToUpper(p.next()) == `AND` {
+		p.pos++
+		f, err = p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	if len(list) == 1 {
+		return list[0], nil
+	}
+	return list, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	switch t := p.next(); {
+	case strings.ToUpper(t) == `NOT`:
+		p.pos++
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return FilterNot(f), nil
+	case t == `(`:
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != `)` {
+			return nil, ErrFilterSyntax
+		}
+		p.pos++
+		return f, nil
+	}
+	if p.pos+3 > len(p.tokens) {
+		return nil, ErrFilterSyntax
+	}
+	key, op, value := p.tokens[p.pos], p.tokens[p.pos+1], p.tokens[p.pos+2]
+	if isFilterOperator(key) || isFilterOperator(value) {
+		return nil, ErrFilterSyntax
+	}
+	p.pos += 3
+	return FilterCompare(key, op, strings.Trim(value, `"`))
+}
+
+func isFilterOperator(t string) bool {
+	switch t {
+	case `=`, `!=`, `>`, `>=`, `<`, `<=`, `(`, `)`:
+		return true
+	}
+	return false
+}
+
+func tokenizeFilter(expr string) []string {
+	tokens := make([]string, 0)
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '!' || r == '>' || r == '<' || r == '=':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else {
+				tokens = append(tokens, string(r))
+				i++
+			}
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			if j < len(runes) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(`()!<>="`, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+	return tokens
+}
+
+type numberPosting struct {
+	value float64
+	item  *indexVectorItem
+}
+
+// attributeIndex maps attribute values to items for pre-filtering.
+type attributeIndex struct {
+	tags    map[string]itemSet
+	numbers map[string][]numberPosting
+}
+
+func newAttributeIndex(items []*indexVectorItem) *attributeIndex {
+	a := &attributeIndex{
+		tags:    make(map[string]itemSet),
+		numbers: make(map[string][]numberPosting),
+	}
+	for _, item := range items {
+		for key, value := range item.i.Attrs.Tags {
+			k := attributeTagKey(key, value)
+			if a.tags[k] == nil {
+				a.tags[k] = make(itemSet)
+			}
+			a.tags[k][item] = struct{}{}
+		}
+		for key, value := range item.i.Attrs.Numbers {
+			a.numbers[key] = append(a.numbers[key], numberPosting{value: value, item: item})
+		}
+	}
+	for _, postings := range a.numbers {
+		sort.Slice(postings, func(i, j int) bool {
+			return postings[i].value < postings[j].value
+		})
+	}
+	return a
+}
+
+func (a *attributeIndex) add(item *indexVectorItem) {
+	for key, value := range item.i.Attrs.Tags {
+		k := attributeTagKey(key, value)
+		if a.tags[k] == nil {
+			a.tags[k] = make(itemSet)
+		}
+		a.tags[k][item] = struct{}{}
+	}
+	for key, value := range item.i.Attrs.Numbers {
+		postings := a.numbers[key]
+		i := sort.Search(len(postings), func(i int) bool { return postings[i].value > value })
+		postings = append(postings, numberPosting{})
+		copy(postings[i+1:], postings[i:])
+		postings[i] = numberPosting{value: value, item: item}
+		a.numbers[key] = postings
+	}
+}
+
+func (a *attributeIndex) remove(item *indexVectorItem) {
+	for key, value := range item.i.Attrs.Tags {
+		delete(a.tags[attributeTagKey(key, value)], item)
+	}
+	for key, value := range item.i.Attrs.Numbers {
+		postings := a.numbers[key]
+		for i := sort.Search(len(postings), func(i int) bool { return postings[i].value >= value }); i < len(postings) && postings[i].value == value; i++ {
+			if postings[i].item == item {
+				a.numbers[key] = append(postings[:i], postings[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func attributeTagKey(key, value string) string {
+	return key + "\x00" + value
+}
+
+// preFilter returns items matching filter when the filter is selective enough,
+// ok is false if search should run over the whole index and filter results.
+func (iv *IndexVector) preFilter(filter Filter) ([]*indexVectorItem, bool) {
+	cf, ok := filter.(candidateFilter)
+	if !ok || iv.attrs == nil {
+		return nil, false
+	}
+	set, ok := cf.candidates(iv.attrs)
+	if !ok || float64(len(set)) > preFilterRatio*float64(len(iv.itemsOrderZ)) {
+		return nil, false
+	}
+	items := make([]*indexVectorItem, 0, len(set))
+	for item := range set {
+		if filter.Match(item.i) {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].z < items[j].z
+	})
+	return items, true
+}
+
+// SearchFilter is Search restricted to vectors matching filter.
+func (iv *IndexVector) SearchFilter(v []float64, filter Filter) ([]*Vector, error) {
+	list, err := iv.Search(v)
+	if err != nil {
+		return nil, err
+	}
+	return filterVectors(list, filter), nil
+}
+
+// SearchNeighborhoodFilter is SearchNeighborhood restricted to vectors matching filter.
+func (iv *IndexVector) SearchNeighborhoodFilter(v []float64, neighborhood []float64, filter Filter) ([]*Vector, error) {
+	min, max := neighborhoodBox(v, neighborhood)
+	return iv.SearchBoxFilter(min, max, filter)
+}
+
+// SearchBoxFilter is SearchBox restricted to vectors matching filter.
+func (iv *IndexVector) SearchBoxFilter(min, max []float64, filter Filter) ([]*Vector, error) {
+	if len(min) != len(max) {
+		return nil, ErrDimensionMismatch
+	}
+	if items, ok := iv.preFilter(filter); ok {
+		result := make([]*Vector, 0)
+		buf := iv.vectorBuffer()
+		for _, item := range items {
+			if inBox(iv.vector(item, buf), min, max) {
+				result = append(result, item.i)
+			}
+		}
+		return result, nil
+	}
+	list, err := iv.SearchBox(min, max)
+	if err != nil {
+		return nil, err
+	}
+	return filterVectors(list, filter), nil
+}
+
+// SearchKNNFilter returns k nearest vectors matching filter, closest first.
+func (iv *IndexVector) SearchKNNFilter(v []float64, k int, filter Filter) ([]*Vector, error) {
+	if k <= 0 {
+		return []*Vector{}, nil
+	}
+	items, ok := iv.preFilter(filter)
+	if !ok {
+		items = make([]*indexVectorItem, 0)
+		for _, item := range iv.itemsOrderZ {
+			if filter.Match(item.i) {
+				items = append(items, item)
+			}
+		}
+	}
+	candidates, err := iv.searchKNNItems(items, v, k)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Vector, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.item.i
+	}
+	return result, nil
+}
+
+func filterVectors(list []*Vector, filter Filter) []*Vector {
+	result := make([]*Vector, 0, len(list))
+	for _, v := range list {
+		if filter.Match(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,150 @@
+package word_index
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	v := NewVector(1, []float64{1}, nil).SetTag(`lang`, `ru`).SetNumber(`year`, 2021)
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{expr: `lang=ru`, match: true},
+		{expr: `lang = en`, match: false},
+		{expr: `lang!=en`, match: true},
+		{expr: `lang=ru AND year>=2020`, match: true},
+		{expr: `lang=ru and year>2021`, match: false},
+		{expr: `lang=en OR year<2022`, match: true},
+		{expr: `NOT lang=ru`, match: false},
+		{expr: `lang=en OR (year=2021 AND NOT lang="en gb")`, match: true},
+		{expr: `title=abc`, match: false},
+		{expr: `title!=abc`, match: true},
+	}
+	for _, test := range tests {
+		f, err := ParseFilter(test.expr)
+		if err != nil {
+			t.Fatalf(`%s: %s`, test.expr, err.Error())
+		}
+		if f.Match(v) != test.match {
+			t.Fatalf(`%s: match not equals %v`, test.expr, test.match)
+		}
+	}
+	for _, expr := range []string{``, `lang`, `lang=`, `(lang=ru`, `lang=ru AND`, `lang~ru`, `lang=ru year=1`} {
+		if _, err := ParseFilter(expr); err != ErrFilterSyntax {
+			t.Fatalf(`%s: expected ErrFilterSyntax`, expr)
+		}
+	}
+}
+
+func TestIndexVector_SearchFilter(t *testing.T) {
+	rnd := rand.New(rand.NewSource(8))
+	langs := []string{`ru`, `en`, `de`}
+	list := make([]*Vector, 1000)
+	for i := range list {
+		list[i] = NewVector(uint32(i), []float64{rnd.Float64() * 10, rnd.Float64() * 10}, nil).
+			SetTag(`lang`, langs[i%len(langs)]).
+			SetNumber(`year`, float64(2000+i%25))
+	}
+	iv, err := NewIndexVector()
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = iv.Fit(list[:900]); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	for _, v := range list[900:] {
+		if err = iv.Add(v); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+	}
+	if err = iv.Remove(3); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	list = append(list[:3], list[4:]...)
+
+	for _, expr := range []string{
+		`lang=ru AND year>=2020`,
+		`year=2003`,
+		`year<2002 OR year>2023`,
+		`lang!=ru`,
+		`NOT year<=2020`,
+	} {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		for n := 0; n < 10; n++ {
+			q := []float64{rnd.Float64() * 10, rnd.Float64() * 10}
+
+			found, err := iv.SearchNeighborhoodFilter(q, []float64{2, 2}, f)
+			if err != nil {
+				t.Fatalf(`%s`, err.Error())
+			}
+			min, max := neighborhoodBox(q, []float64{2, 2})
+			count := 0
+			for _, v := range list {
+				if f.Match(v) && inBox(v.V, min, max) {
+					count++
+				}
+			}
+			if count != len(found) {
+				t.Fatalf(`%s: found %d != %d`, expr, len(found), count)
+			}
+
+			knn, err := iv.SearchKNNFilter(q, 5, f)
+			if err != nil {
+				t.Fatalf(`%s`, err.Error())
+			}
+			if len(knn) != 5 {
+				t.Fatalf(`%s: len not equals 5, %d`, expr, len(knn))
+			}
+			worst := distEuclidean(knn[len(knn)-1].V, q)
+			for _, v := range knn {
+				if !f.Match(v) {
+					t.Fatalf(`%s: vector %d does not match filter`, expr, v.Id)
+				}
+			}
+			closer := 0
+			for _, v := range list {
+				if f.Match(v) && distEuclidean(v.V, q) < worst {
+					closer++
+				}
+			}
+			if closer > 4 {
+				t.Fatalf(`%s: knn misses %d closer vectors`, expr, closer-4)
+			}
+		}
+	}
+
+	found, err := iv.SearchFilter(list[0].V, FilterFunc(func(v *Vector) bool {
+		return v.Id == list[0].Id
+	}))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(found) != 1 || found[0].Id != list[0].Id {
+		t.Fatalf(`vector %d not found`, list[0].Id)
+	}
+}
+
+func TestIndexVector_PreFilter(t *testing.T) {
+	list := make([]*Vector, 100)
+	for i := range list {
+		list[i] = NewVector(uint32(i), []float64{float64(i)}, nil).SetNumber(`n`, float64(i))
+	}
+	iv, _ := NewIndexVector()
+	if err := iv.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	f, _ := ParseFilter(`n>=10 AND n<13`)
+	items, ok := iv.preFilter(f)
+	if !ok || len(items) != 3 {
+		t.Fatalf(`selective filter must use pre-filtering`)
+	}
+	f, _ = ParseFilter(`n>=10`)
+	if _, ok = iv.preFilter(f); ok {
+		t.Fatalf(`non-selective filter must not use pre-filtering`)
+	}
+}
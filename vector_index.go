@@ -1,14 +1,28 @@
 package word_index
 
 import (
+	"context"
+	"errors"
 	"math"
+	"runtime"
 	"sort"
 )
 
+var (
+	ErrDimensionMismatch = errors.New(`vector dimensions mismatch`)
+	ErrInvalidThreshold  = errors.New(`invalid neighbors threshold`)
+	ErrVectorNotFound    = errors.New(`vector not found`)
+	ErrNeighborsDisabled = errors.New(`neighbors graph is disabled`)
+	ErrVectorExists      = errors.New(`vector already exists`)
+	ErrInvalidMetric     = errors.New(`invalid metric`)
+	ErrInvalidWorkers    = errors.New(`invalid number of workers`)
+)
+
 type Vector struct {
-	Id   uint32
-	V    []float64
-	Data interface{}
+	Id    uint32
+	V     []float64
+	Data  interface{}
+	Attrs Attributes
 }
 
 func (v *Vector) DistCos(a *Vector) float64 {
@@ -23,6 +37,37 @@ func (v *Vector) DistEuclidean(a *Vector) float64 {
 	return distEuclidean(a.V, v.V)
 }
 
+// Metric measures distance between vectors, smaller value means closer vectors.
+type Metric interface {
+	Distance(a, b []float64) float64
+}
+
+type metricEuclidean struct{}
+
+func (metricEuclidean) Distance(a, b []float64) float64 {
+	return distEuclidean(a, b)
+}
+
+type metricCosine struct{}
+
+func (metricCosine) Distance(a, b []float64) float64 {
+	return 1 - distCos(a, b)
+}
+
+var (
+	MetricEuclidean Metric = metricEuclidean{}
+	MetricCosine    Metric = metricCosine{}
+)
+
+// VectorIndex is implemented by IndexVector and IndexIVF.
+type VectorIndex interface {
+	Fit([]*Vector) error
+	Add(*Vector) error
+	Remove(uint32) error
+	Update(*Vector) error
+	SearchKNN([]float64, int) ([]*Vector, error)
+}
+
 func NewEmptyVector(id uint32, size int) *Vector {
 	return &Vector{
 		Id: id,
@@ -41,6 +86,7 @@ func NewVector(id uint32, v []float64, data interface{}) *Vector {
 type indexVectorItem struct {
 	i         *Vector
 	z         uint64
+	code      []byte
 	neighbors []*indexVectorItem
 }
 
@@ -48,72 +94,189 @@ type IndexVector struct {
 	itemsMap           map[uint32]*indexVectorItem
 	itemsOrderZ        []*indexVectorItem
 	neighborsThreshold float64
+	pq                 *ProductQuantizer
+	pqRerank           int
+	metric             Metric
+	attrs              *attributeIndex
+	workers            int
 }
 
 func (iv *IndexVector) Fit(list []*Vector) error {
+	if iv.pq != nil && !iv.pq.Trained() {
+		vectors := make([][]float64, len(list))
+		for i, v := range list {
+			vectors[i] = v.V
+		}
+		if err := iv.pq.Train(vectors); err != nil {
+			return err
+		}
+	}
+
+	// in compact mode searches see only decoded vectors, so z-codes and the graph
+	// are built from decoded vectors as in Add, originals are dropped after linking
+	compact := iv.pq != nil && iv.pqRerank == 0
 	items := make([]*indexVectorItem, len(list))
-	itemsMap := make(map[uint32]*indexVectorItem)
-	for i, v := range list {
-		item := &indexVectorItem{
-			i: v,
-			z: ZOrderCurveFloat64(v.V),
+	err := parallelRange(len(list), iv.workers, func(low, high int) error {
+		for i := low; i < high; i++ {
+			item := &indexVectorItem{i: list[i]}
+			if err := iv.encode(item); err != nil {
+				return err
+			}
+			if compact {
+				v := list[i]
+				item.i = &Vector{Id: v.Id, V: iv.pq.Decode(item.code), Data: v.Data, Attrs: v.Attrs}
+			}
+			item.z = ZOrderCurveFloat64(item.i.V)
+			items[i] = item
 		}
-		items[i] = item
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	itemsMap := make(map[uint32]*indexVectorItem)
+	for _, item := range items {
 		itemsMap[item.i.Id] = item
 	}
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].z < items[j].z
 	})
 
-
 	if iv.neighborsThreshold != 0 {
-		// update neighbors O(N^2)
-		for i, v := range itemsMap {
-			v.neighbors = make([]*indexVectorItem, 0)
-			for j, v1 := range itemsMap {
-				if i == j {
-					continue
-				}
-				// TODO set sist type
-				if v.i.DistEuclidean(v1.i) <= iv.neighborsThreshold {
-					v.neighbors = append(v.neighbors, v1)
-				}
-			}
+		buildNeighbors(items, iv.neighborsThreshold, iv.workers)
+	}
+	if compact {
+		for _, item := range items {
+			item.i.V = nil
 		}
 	}
 
-
 	iv.itemsMap = itemsMap
 	iv.itemsOrderZ = items
+	iv.attrs = newAttributeIndex(items)
 
 	return nil
 }
 
 func (iv *IndexVector) SearchNeighborhood(v []float64, neighborhood []float64) ([]*Vector, error) {
-	zSearch := ZOrderCurveFloat64(v)
-	zNeighborhood := ZOrderCurveFloat64(neighborhood)
-	zSearchLow := uint64(0)
-	if zSearch > zNeighborhood {
-		zSearchLow = zSearch - zNeighborhood
+	min, max := neighborhoodBox(v, neighborhood)
+	return iv.SearchBox(min, max)
+}
+
+// neighborhoodBox returns box v ± neighborhood, missing neighborhood coordinates are zero.
+func neighborhoodBox(v []float64, neighborhood []float64) ([]float64, []float64) {
+	min := make([]float64, len(v))
+	max := make([]float64, len(v))
+	for i, x := range v {
+		d := float64(0)
+		if i < len(neighborhood) {
+			d = math.Abs(neighborhood[i])
+		}
+		min[i] = x - d
+		max[i] = x + d
 	}
-	zSearchHigh := zSearch + zNeighborhood
-	low := 0
+	return min, max
+}
+
+// SearchBox returns vectors inside the axis-aligned box [min, max] (bounds inclusive).
+// Z-order ranges are walked with BIGMIN jumps, so cells outside the box are skipped.
+func (iv *IndexVector) SearchBox(min, max []float64) ([]*Vector, error) {
+	return iv.SearchBoxContext(context.Background(), min, max)
+}
+
+// SearchNeighborhoodContext is SearchNeighborhood returning ctx.Err() if ctx is done during the scan.
+func (iv *IndexVector) SearchNeighborhoodContext(ctx context.Context, v []float64, neighborhood []float64) ([]*Vector, error) {
+	min, max := neighborhoodBox(v, neighborhood)
+	return iv.SearchBoxContext(ctx, min, max)
+}
+
+// SearchBoxContext is SearchBox returning ctx.Err() if ctx is done during the scan.
+func (iv *IndexVector) SearchBoxContext(ctx context.Context, min, max []float64) ([]*Vector, error) {
+	items, err := iv.searchBoxContext(ctx, min, max)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Vector, len(items))
+	for i, item := range items {
+		result[i] = item.i
+	}
+	return result, nil
+}
+
+func (iv *IndexVector) searchBox(min, max []float64) ([]*indexVectorItem, error) {
+	return iv.searchBoxContext(context.Background(), min, max)
+}
+
+func (iv *IndexVector) searchBoxContext(ctx context.Context, min, max []float64) ([]*indexVectorItem, error) {
+	if len(min) != len(max) {
+		return nil, ErrDimensionMismatch
+	}
+	for i := range min {
+		if min[i] > max[i] {
+			return []*indexVectorItem{}, nil
+		}
+	}
+
+	result := make([]*indexVectorItem, 0)
+	buf := iv.vectorBuffer()
+	if !zOrderBoxSupported(min, max) {
+		for n, item := range iv.itemsOrderZ {
+			if n%contextCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			if inBox(iv.vector(item, buf), min, max) {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	}
+
+	zMin := ZOrderCurveFloat64(min)
+	zMax := ZOrderCurveFloat64(max)
+	qMin, qMax := [2]uint64{}, [2]uint64{}
+	for i := range min {
+		qMin[i] = zOrderCurveFloat64ToUint64(min[i])
+		qMax[i] = zOrderCurveFloat64ToUint64(max[i])
+	}
+
+	low := iv.lowerBoundZ(zMin, 0)
+	for n := 0; low < len(iv.itemsOrderZ) && iv.itemsOrderZ[low].z <= zMax; n++ {
+		if n%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		item := iv.itemsOrderZ[low]
+		x, y := zOrderDecode2(item.z)
+		if x >= qMin[0] && x <= qMax[0] && y >= qMin[1] && y <= qMax[1] {
+			if inBox(iv.vector(item, buf), min, max) {
+				result = append(result, item)
+			}
+			low++
+			continue
+		}
+		next, ok := zOrderBigMin(item.z, zMin, zMax)
+		if !ok {
+			break
+		}
+		low = iv.lowerBoundZ(next, low)
+	}
+	return result, nil
+}
+
+func (iv *IndexVector) lowerBoundZ(z uint64, low int) int {
 	high := len(iv.itemsOrderZ) - 1
 	for low <= high {
 		median := (low + high) / 2
-		if iv.itemsOrderZ[median].z < zSearchLow {
+		if iv.itemsOrderZ[median].z < z {
 			low = median + 1
 		} else {
 			high = median - 1
 		}
 	}
-	result := make([]*Vector, 0)
-	for low < len(iv.itemsOrderZ) && iv.itemsOrderZ[low].z <= zSearchHigh {
-		//fmt.Println(iv.itemsOrderZ[low].i.Id)
-		result = append(result, iv.itemsOrderZ[low].i)
-		low++
-	}
-	return result, nil
+	return low
 }
 
 func (iv *IndexVector) Search(v []float64) ([]*Vector, error) {
@@ -137,8 +300,235 @@ func (iv *IndexVector) Search(v []float64) ([]*Vector, error) {
 	return result, nil
 }
 
-func NewIndexVector() (*IndexVector, error) {
-	return &IndexVector{}, nil
+// Add inserts v keeping itemsOrderZ sorted, neighbor lists are updated locally.
+func (iv *IndexVector) Add(v *Vector) error {
+	if _, ok := iv.itemsMap[v.Id]; ok {
+		return ErrVectorExists
+	}
+	item := &indexVectorItem{i: v}
+	if err := iv.encode(item); err != nil {
+		return err
+	}
+	iv.insert(item)
+	return nil
+}
+
+// insert links encoded item into the index, it never fails so a removed vector can be replaced safely.
+func (iv *IndexVector) insert(item *indexVectorItem) {
+	v := item.i
+	if iv.itemsMap == nil {
+		iv.itemsMap = make(map[uint32]*indexVectorItem)
+	}
+	// in compact mode z-code and neighbors are computed from decoded vector as in Fit
+	center := v.V
+	if iv.pq != nil && iv.pqRerank == 0 {
+		center = iv.pq.Decode(item.code)
+	}
+	item.z = ZOrderCurveFloat64(center)
+
+	if iv.neighborsThreshold != 0 {
+		item.neighbors = make([]*indexVectorItem, 0)
+		min := make([]float64, len(center))
+		max := make([]float64, len(center))
+		for i, x := range center {
+			min[i], max[i] = x-iv.neighborsThreshold, x+iv.neighborsThreshold
+		}
+		// box bounds have equal dimensions, searchBox can not fail
+		list, _ := iv.searchBox(min, max)
+		buf := iv.vectorBuffer()
+		for _, n := range list {
+			if distEuclidean(iv.vector(n, buf), center) <= iv.neighborsThreshold {
+				item.neighbors = append(item.neighbors, n)
+				n.neighbors = append(n.neighbors, item)
+			}
+		}
+	}
+
+	low := iv.lowerBoundZ(item.z+1, 0)
+	if item.z == math.MaxUint64 {
+		low = len(iv.itemsOrderZ)
+	}
+	if iv.pq != nil && iv.pqRerank == 0 {
+		item.i = &Vector{Id: v.Id, Data: v.Data, Attrs: v.Attrs}
+	}
+	iv.itemsOrderZ = append(iv.itemsOrderZ, nil)
+	copy(iv.itemsOrderZ[low+1:], iv.itemsOrderZ[low:])
+	iv.itemsOrderZ[low] = item
+	iv.itemsMap[v.Id] = item
+	if iv.attrs == nil {
+		iv.attrs = newAttributeIndex(nil)
+	}
+	iv.attrs.add(item)
+}
+
+// Remove deletes the vector id from the index and from neighbor lists.
+func (iv *IndexVector) Remove(id uint32) error {
+	item, ok := iv.itemsMap[id]
+	if !ok {
+		return ErrVectorNotFound
+	}
+	for _, n := range item.neighbors {
+		for j, nn := range n.neighbors {
+			if nn == item {
+				n.neighbors = append(n.neighbors[:j], n.neighbors[j+1:]...)
+				break
+			}
+		}
+	}
+	for low := iv.lowerBoundZ(item.z, 0); low < len(iv.itemsOrderZ) && iv.itemsOrderZ[low].z == item.z; low++ {
+		if iv.itemsOrderZ[low] == item {
+			iv.itemsOrderZ = append(iv.itemsOrderZ[:low], iv.itemsOrderZ[low+1:]...)
+			break
+		}
+	}
+	delete(iv.itemsMap, id)
+	if iv.attrs != nil {
+		iv.attrs.remove(item)
+	}
+	return nil
+}
+
+// Update replaces the vector with the same id, the old vector is kept when v can not be added.
+func (iv *IndexVector) Update(v *Vector) error {
+	if _, ok := iv.itemsMap[v.Id]; !ok {
+		return ErrVectorNotFound
+	}
+	item := &indexVectorItem{i: v}
+	if err := iv.encode(item); err != nil {
+		return err
+	}
+	if err := iv.Remove(v.Id); err != nil {
+		return err
+	}
+	iv.insert(item)
+	return nil
+}
+
+// clone returns a copy of the index sharing only immutable vectors with iv.
+func (iv *IndexVector) clone() *IndexVector {
+	next := *iv
+	items := make(map[*indexVectorItem]*indexVectorItem, len(iv.itemsOrderZ))
+	next.itemsOrderZ = make([]*indexVectorItem, len(iv.itemsOrderZ))
+	for i, item := range iv.itemsOrderZ {
+		copied := *item
+		items[item] = &copied
+		next.itemsOrderZ[i] = &copied
+	}
+	for _, item := range next.itemsOrderZ {
+		if item.neighbors == nil {
+			continue
+		}
+		neighbors := make([]*indexVectorItem, len(item.neighbors))
+		for i, n := range item.neighbors {
+			neighbors[i] = items[n]
+		}
+		item.neighbors = neighbors
+	}
+	next.itemsMap = make(map[uint32]*indexVectorItem, len(iv.itemsMap))
+	for id, item := range iv.itemsMap {
+		next.itemsMap[id] = items[item]
+	}
+	next.attrs = newAttributeIndex(next.itemsOrderZ)
+	return &next
+}
+
+// encode stores product quantization code of the item when quantizer is set.
+func (iv *IndexVector) encode(item *indexVectorItem) error {
+	if iv.pq == nil {
+		return nil
+	}
+	code, err := iv.pq.Encode(item.i.V)
+	if err != nil {
+		return err
+	}
+	item.code = code
+	return nil
+}
+
+// vector returns original coordinates of the item or reconstructs them from the code into buf,
+// the result is valid until the next call with the same buf.
+func (iv *IndexVector) vector(item *indexVectorItem, buf []float64) []float64 {
+	if item.i.V == nil && item.code != nil {
+		return iv.pq.decodeTo(buf, item.code)
+	}
+	return item.i.V
+}
+
+// vectorBuffer returns buffer for vector, it is nil unless originals are dropped.
+func (iv *IndexVector) vectorBuffer() []float64 {
+	if iv.pq == nil || iv.pqRerank != 0 {
+		return nil
+	}
+	return make([]float64, 0, iv.pq.dim)
+}
+
+// IndexVectorOption configures IndexVector in NewIndexVector.
+type IndexVectorOption func(*IndexVector) error
+
+// WithNeighborsThreshold enables the neighbor graph: Fit links every pair of vectors
+// with Euclidean distance not greater than threshold.
+func WithNeighborsThreshold(threshold float64) IndexVectorOption {
+	return func(iv *IndexVector) error {
+		if threshold < 0 || math.IsNaN(threshold) {
+			return ErrInvalidThreshold
+		}
+		iv.neighborsThreshold = threshold
+		return nil
+	}
+}
+
+// WithProductQuantizer stores vectors as product quantization codes, pq is trained in Fit
+// if it is not trained yet. With rerank > 0 original vectors are kept and SearchKNN
+// re-ranks rerank*k nearest codes by exact distance, with rerank == 0 originals are dropped
+// and returned vectors contain only Id and Data, box, radius searches and the neighbor graph
+// then work with decoded vectors.
+func WithProductQuantizer(pq *ProductQuantizer, rerank int) IndexVectorOption {
+	return func(iv *IndexVector) error {
+		if pq == nil || rerank < 0 {
+			return ErrInvalidQuantizer
+		}
+		iv.pq = pq
+		iv.pqRerank = rerank
+		return nil
+	}
+}
+
+// WithMetric sets metric of SearchKNN, Euclidean is used by default.
+// Neighbor graph, SearchRadius and product quantization codes are always Euclidean.
+func WithMetric(metric Metric) IndexVectorOption {
+	return func(iv *IndexVector) error {
+		if metric == nil {
+			return ErrInvalidMetric
+		}
+		iv.metric = metric
+		return nil
+	}
+}
+
+// WithWorkers sets number of goroutines used by Fit and SearchBatch, GOMAXPROCS by default.
+func WithWorkers(workers int) IndexVectorOption {
+	return func(iv *IndexVector) error {
+		if workers < 1 {
+			return ErrInvalidWorkers
+		}
+		iv.workers = workers
+		return nil
+	}
+}
+
+// SearchBatch runs SearchKNN for every query concurrently, see SearchBatch.
+func (iv *IndexVector) SearchBatch(ctx context.Context, queries [][]float64, k int) ([][]*Vector, error) {
+	return SearchBatch(ctx, iv, queries, k, iv.workers)
+}
+
+func NewIndexVector(opts ...IndexVectorOption) (*IndexVector, error) {
+	iv := &IndexVector{metric: MetricEuclidean, workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		if err := opt(iv); err != nil {
+			return nil, err
+		}
+	}
+	return iv, nil
 }
 
 func ZOrderCurveFloat64(vec []float64) uint64 {
@@ -169,6 +559,69 @@ func ZOrderCurve(vec []uint64) uint64 {
 	return r
 }
 
+// zOrderBoxSupported reports whether the box maps onto a valid Morton range.
+// ZOrderCurve interleaves the low 32 bits of at most two coordinates without collisions.
+func zOrderBoxSupported(min, max []float64) bool {
+	if len(min) == 0 || len(min) > 2 {
+		return false
+	}
+	for i := range min {
+		if min[i] < 0 || max[i]*1000000 >= 1<<32 {
+			return false
+		}
+	}
+	return true
+}
+
+func inBox(v, min, max []float64) bool {
+	if len(v) != len(min) {
+		return false
+	}
+	for i, x := range v {
+		if x < min[i] || x > max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func zOrderDecode2(z uint64) (uint64, uint64) {
+	return zOrderCompact(z), zOrderCompact(z >> 1)
+}
+
+func zOrderCompact(z uint64) uint64 {
+	z &= 0x5555555555555555
+	z = (z | (z >> 1)) & 0x3333333333333333
+	z = (z | (z >> 2)) & 0x0F0F0F0F0F0F0F0F
+	z = (z | (z >> 4)) & 0x00FF00FF00FF00FF
+	z = (z | (z >> 8)) & 0x0000FFFF0000FFFF
+	z = (z | (z >> 16)) & 0x00000000FFFFFFFF
+	return z
+}
+
+// zOrderBigMin returns the smallest Morton code greater than z that lies inside
+// the box spanned by zMin and zMax (Tropf, Herzog 1981).
+func zOrderBigMin(z, zMin, zMax uint64) (uint64, bool) {
+	bigMin, found := uint64(0), false
+	for p := 63; p >= 0; p-- {
+		mask := uint64(1) << uint(p)
+		lower := (uint64(0x5555555555555555) << uint(p%2)) & (mask - 1)
+		zb, minb, maxb := z&mask != 0, zMin&mask != 0, zMax&mask != 0
+		switch {
+		case !zb && !minb && maxb:
+			bigMin, found = (zMin|mask)&^lower, true
+			zMax = (zMax &^ mask) | lower
+		case !zb && minb && maxb:
+			return zMin, true
+		case zb && !minb && !maxb:
+			return bigMin, found
+		case zb && !minb && maxb:
+			zMin = (zMin | mask) &^ lower
+		}
+	}
+	return bigMin, found
+}
+
 func distCos(a, b []float64) float64 {
 	if len(a) != len(b) {
 		return 0
@@ -190,11 +643,32 @@ func distEuclidean(a, b []float64) float64 {
 	if len(a) != len(b) {
 		return 0
 	}
-	s := float64(0)
-	for i := 0; i < len(a); i++ {
-		s += math.Pow(a[i]-b[i], 2)
+	return math.Sqrt(distEuclideanSquare(a, b))
+}
+
+// distEuclideanSquare returns squared Euclidean distance over common coordinates of a and b.
+func distEuclideanSquare(a, b []float64) float64 {
+	if len(b) < len(a) {
+		a = a[:len(b)]
+	}
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float64
+	n := len(a) &^ 3
+	for i := 0; i < n; i += 4 {
+		d0 := a[i] - b[i]
+		d1 := a[i+1] - b[i+1]
+		d2 := a[i+2] - b[i+2]
+		d3 := a[i+3] - b[i+3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for i := n; i < len(a); i++ {
+		d := a[i] - b[i]
+		s0 += d * d
 	}
-	return math.Sqrt(s)
+	return s0 + s1 + s2 + s3
 }
 
 func distMonteCarlo(a, b []float64) float64 {
@@ -0,0 +1,202 @@
+package word_index
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// IndexVectorSync is safe for concurrent use. Writes are applied to a copy
+// of the current IndexVector which is then published by atomic pointer swap,
+// so searches never wait for writers. A copy costs O(N), so writes waiting
+// for the lock are applied together to one copy, and AddBatch, RemoveBatch
+// and Apply group many writes of one caller.
+type IndexVectorSync struct {
+	current atomic.Value
+	mx      sync.Mutex
+
+	pendingMx sync.Mutex
+	pending   []*vectorWrite
+}
+
+// vectorWrite is a queued write, its result is sent to done.
+type vectorWrite struct {
+	fn   func(iv *IndexVector) error
+	done chan error
+}
+
+// VectorReader is the read-only part of IndexVector.
+type VectorReader interface {
+	Search([]float64) ([]*Vector, error)
+	SearchBox(min, max []float64) ([]*Vector, error)
+	SearchBoxContext(ctx context.Context, min, max []float64) ([]*Vector, error)
+	SearchNeighborhood(v []float64, neighborhood []float64) ([]*Vector, error)
+	SearchNeighborhoodContext(ctx context.Context, v []float64, neighborhood []float64) ([]*Vector, error)
+	SearchRadius([]float64, float64) ([]*Vector, error)
+	SearchKNN([]float64, int) ([]*Vector, error)
+	SearchBatch(context.Context, [][]float64, int) ([][]*Vector, error)
+	SearchFilter([]float64, Filter) ([]*Vector, error)
+	SearchBoxFilter(min, max []float64, filter Filter) ([]*Vector, error)
+	SearchNeighborhoodFilter(v []float64, neighborhood []float64, filter Filter) ([]*Vector, error)
+	SearchKNNFilter([]float64, int, Filter) ([]*Vector, error)
+	Neighbors(uint32) ([]*Vector, error)
+}
+
+// indexVectorSnapshot hides the published IndexVector, so it can't be asserted and modified.
+type indexVectorSnapshot struct {
+	VectorReader
+}
+
+// Snapshot returns read-only view of the current index, later writes are not visible.
+func (s *IndexVectorSync) Snapshot() VectorReader {
+	return indexVectorSnapshot{VectorReader: s.snapshot()}
+}
+
+func (s *IndexVectorSync) snapshot() *IndexVector {
+	return s.current.Load().(*IndexVector)
+}
+
+// Apply runs fn on a copy of the current index, changes of fn are published only if fn succeeds.
+func (s *IndexVectorSync) Apply(fn func(iv *IndexVector) error) error {
+	w := &vectorWrite{fn: fn, done: make(chan error, 1)}
+	s.pendingMx.Lock()
+	s.pending = append(s.pending, w)
+	s.pendingMx.Unlock()
+
+	s.mx.Lock()
+	s.pendingMx.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.pendingMx.Unlock()
+	if len(batch) > 0 {
+		s.applyBatch(batch)
+	}
+	s.mx.Unlock()
+	return <-w.done
+}
+
+// applyBatch applies queued writes to one copy of the index. A failed write may leave
+// the copy partially changed, then the copy is rebuilt from the writes succeeded before it.
+func (s *IndexVectorSync) applyBatch(batch []*vectorWrite) {
+	current := s.snapshot()
+	next := current.clone()
+	applied := make([]*vectorWrite, 0, len(batch))
+	for _, w := range batch {
+		err := w.fn(next)
+		if err != nil {
+			next = current.clone()
+			for _, a := range applied {
+				a.fn(next)
+			}
+		} else {
+			applied = append(applied, w)
+		}
+		w.done <- err
+	}
+	if len(applied) > 0 {
+		s.current.Store(next)
+	}
+}
+
+// Fit replaces the index, a quantizer shared with the current snapshot is trained on a copy.
+func (s *IndexVectorSync) Fit(list []*Vector) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	next := *s.snapshot()
+	if next.pq != nil {
+		next.pq = next.pq.clone()
+	}
+	if err := next.Fit(list); err != nil {
+		return err
+	}
+	s.current.Store(&next)
+	return nil
+}
+
+func (s *IndexVectorSync) Add(v *Vector) error {
+	return s.Apply(func(iv *IndexVector) error {
+		return iv.Add(v)
+	})
+}
+
+// AddBatch adds all vectors with one copy of the index, nothing is added on error.
+func (s *IndexVectorSync) AddBatch(list []*Vector) error {
+	return s.Apply(func(iv *IndexVector) error {
+		for _, v := range list {
+			if err := iv.Add(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *IndexVectorSync) Remove(id uint32) error {
+	return s.Apply(func(iv *IndexVector) error {
+		return iv.Remove(id)
+	})
+}
+
+// RemoveBatch removes all vectors with one copy of the index, nothing is removed on error.
+func (s *IndexVectorSync) RemoveBatch(ids []uint32) error {
+	return s.Apply(func(iv *IndexVector) error {
+		for _, id := range ids {
+			if err := iv.Remove(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *IndexVectorSync) Update(v *Vector) error {
+	return s.Apply(func(iv *IndexVector) error {
+		return iv.Update(v)
+	})
+}
+
+func (s *IndexVectorSync) Search(v []float64) ([]*Vector, error) {
+	return s.snapshot().Search(v)
+}
+
+func (s *IndexVectorSync) SearchBox(min, max []float64) ([]*Vector, error) {
+	return s.snapshot().SearchBox(min, max)
+}
+
+func (s *IndexVectorSync) SearchNeighborhood(v []float64, neighborhood []float64) ([]*Vector, error) {
+	return s.snapshot().SearchNeighborhood(v, neighborhood)
+}
+
+func (s *IndexVectorSync) SearchNeighborhoodContext(ctx context.Context, v []float64, neighborhood []float64) ([]*Vector, error) {
+	return s.snapshot().SearchNeighborhoodContext(ctx, v, neighborhood)
+}
+
+func (s *IndexVectorSync) SearchBoxContext(ctx context.Context, min, max []float64) ([]*Vector, error) {
+	return s.snapshot().SearchBoxContext(ctx, min, max)
+}
+
+func (s *IndexVectorSync) SearchRadius(v []float64, r float64) ([]*Vector, error) {
+	return s.snapshot().SearchRadius(v, r)
+}
+
+func (s *IndexVectorSync) SearchKNN(v []float64, k int) ([]*Vector, error) {
+	return s.snapshot().SearchKNN(v, k)
+}
+
+func (s *IndexVectorSync) SearchBatch(ctx context.Context, queries [][]float64, k int) ([][]*Vector, error) {
+	return s.snapshot().SearchBatch(ctx, queries, k)
+}
+
+func (s *IndexVectorSync) Neighbors(id uint32) ([]*Vector, error) {
+	return s.snapshot().Neighbors(id)
+}
+
+func NewIndexVectorSync(opts ...IndexVectorOption) (*IndexVectorSync, error) {
+	iv, err := NewIndexVector(opts...)
+	if err != nil {
+		return nil, err
+	}
+	s := &IndexVectorSync{}
+	s.current.Store(iv)
+	return s, nil
+}
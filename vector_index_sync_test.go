@@ -0,0 +1,144 @@
+package word_index
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestIndexVectorSync_Concurrent(t *testing.T) {
+	list := randomVectors(600, 2, 13)
+	index, err := NewIndexVectorSync(WithNeighborsThreshold(0.05))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = index.Fit(list[:300]); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, v := range list[300:] {
+			if err := index.Add(v); err != nil {
+				t.Errorf(`%s`, err.Error())
+				return
+			}
+		}
+		for _, v := range list[:100] {
+			if err := index.Remove(v.Id); err != nil {
+				t.Errorf(`%s`, err.Error())
+				return
+			}
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for n := 0; n < 200; n++ {
+				q := list[(n*7+r)%len(list)].V
+				if _, err := index.SearchKNN(q, 3); err != nil {
+					t.Errorf(`%s`, err.Error())
+					return
+				}
+				if _, err := index.SearchRadius(q, 0.03); err != nil {
+					t.Errorf(`%s`, err.Error())
+					return
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+	if _, ok := index.Snapshot().(VectorIndex); ok {
+		t.Fatalf(`snapshot must be read-only`)
+	}
+
+	snapshot := index.snapshot()
+	if len(snapshot.itemsOrderZ) != 500 {
+		t.Fatalf(`len not equals 500, %d`, len(snapshot.itemsOrderZ))
+	}
+	expected, _ := NewIndexVector(WithNeighborsThreshold(0.05))
+	expected.Fit(list[100:])
+	for _, v := range list[100:] {
+		a, _ := snapshot.Neighbors(v.Id)
+		b, _ := expected.Neighbors(v.Id)
+		if len(a) != len(b) {
+			t.Fatalf(`id %d: neighbors %d != %d`, v.Id, len(a), len(b))
+		}
+	}
+
+	err = index.Apply(func(iv *IndexVector) error {
+		if err := iv.Remove(list[100].Id); err != nil {
+			return err
+		}
+		return iv.Remove(list[100].Id)
+	})
+	if err != ErrVectorNotFound {
+		t.Fatalf(`expected ErrVectorNotFound`)
+	}
+	if _, ok := index.snapshot().itemsMap[list[100].Id]; !ok {
+		t.Fatalf(`failed Apply must not be published`)
+	}
+}
+
+func TestIndexVectorSync_Batch(t *testing.T) {
+	list := randomVectors(400, 2, 14)
+	index, _ := NewIndexVectorSync(WithNeighborsThreshold(0.05))
+	if err := index.AddBatch(list[:100]); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err := index.AddBatch([]*Vector{list[100], list[0]}); err != ErrVectorExists {
+		t.Fatalf(`expected ErrVectorExists, %v`, err)
+	}
+	if _, ok := index.snapshot().itemsMap[list[100].Id]; ok {
+		t.Fatalf(`failed batch must not be published`)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 6; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 100 + w; i < len(list); i += 6 {
+				if err := index.Add(list[i]); err != nil {
+					t.Errorf(`%s`, err.Error())
+				}
+				// a failing write grouped with others must not discard them
+				if err := index.Add(list[0]); err != ErrVectorExists {
+					t.Errorf(`expected ErrVectorExists, %v`, err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if n := len(index.snapshot().itemsMap); n != len(list) {
+		t.Fatalf(`vectors not equals %d, %d`, len(list), n)
+	}
+
+	if err := index.RemoveBatch([]uint32{list[1].Id, 100000}); err != ErrVectorNotFound {
+		t.Fatalf(`expected ErrVectorNotFound, %v`, err)
+	}
+	if err := index.RemoveBatch([]uint32{list[1].Id, list[2].Id}); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if n := len(index.snapshot().itemsMap); n != len(list)-2 {
+		t.Fatalf(`vectors not equals %d, %d`, len(list)-2, n)
+	}
+}
+
+func TestIndexVectorSync_FitQuantizer(t *testing.T) {
+	list := randomVectors(300, 4, 15)
+	pq, _ := NewProductQuantizer(4, 2, 16)
+	index, _ := NewIndexVectorSync(WithProductQuantizer(pq, 0))
+	old := index.snapshot()
+	if err := index.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if old.pq.Trained() {
+		t.Fatalf(`quantizer of the old snapshot must not be trained in place`)
+	}
+	if !index.snapshot().pq.Trained() {
+		t.Fatalf(`quantizer of the new snapshot must be trained`)
+	}
+}
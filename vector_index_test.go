@@ -3,6 +3,7 @@ package word_index
 import (
 	"fmt"
 	"math"
+	"math/rand"
 	"sort"
 	"testing"
 )
@@ -99,6 +100,142 @@ func TestNewIndexVector_SearchNeighborhood(t *testing.T) {
 	}
 }
 
+func TestIndexVector_SearchBox(t *testing.T) {
+	iv, err := NewIndexVector()
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for _, dim := range []int{1, 2, 3} {
+		list := make([]*Vector, 500)
+		for i := range list {
+			v := make([]float64, dim)
+			for j := range v {
+				v[j] = rnd.Float64() * 100
+			}
+			list[i] = NewVector(uint32(i), v, nil)
+		}
+		if err = iv.Fit(list); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		for n := 0; n < 50; n++ {
+			min := make([]float64, dim)
+			max := make([]float64, dim)
+			for j := 0; j < dim; j++ {
+				a, b := rnd.Float64()*100, rnd.Float64()*100
+				if a > b {
+					a, b = b, a
+				}
+				min[j], max[j] = a, b
+			}
+			found, err := iv.SearchBox(min, max)
+			if err != nil {
+				t.Fatalf(`%s`, err.Error())
+			}
+			ids := make(map[uint32]struct{})
+			for _, v := range found {
+				if !inBox(v.V, min, max) {
+					t.Fatalf(`dim %d: vector %d outside box`, dim, v.Id)
+				}
+				ids[v.Id] = struct{}{}
+			}
+			for _, v := range list {
+				if _, ok := ids[v.Id]; !ok && inBox(v.V, min, max) {
+					t.Fatalf(`dim %d: vector %d inside box not found`, dim, v.Id)
+				}
+			}
+		}
+	}
+
+	if _, err = iv.SearchBox([]float64{1}, []float64{1, 2}); err != ErrDimensionMismatch {
+		t.Fatalf(`expected ErrDimensionMismatch`)
+	}
+}
+
+func TestZOrderBigMin(t *testing.T) {
+	// example from Tropf, Herzog: box (3,5)-(5,10), z 58 -> 74
+	zMin := ZOrderCurve([]uint64{3, 5})
+	zMax := ZOrderCurve([]uint64{5, 10})
+	next, ok := zOrderBigMin(58, zMin, zMax)
+	if !ok {
+		t.Fatalf(`bigmin not found`)
+	}
+	x, y := zOrderDecode2(next)
+	if x < 3 || x > 5 || y < 5 || y > 10 {
+		t.Fatalf(`bigmin %d outside box`, next)
+	}
+	for z := uint64(59); z < next; z++ {
+		x, y := zOrderDecode2(z)
+		if x >= 3 && x <= 5 && y >= 5 && y <= 10 {
+			t.Fatalf(`bigmin %d skips %d`, next, z)
+		}
+	}
+}
+
+func TestIndexVector_AddRemoveUpdate(t *testing.T) {
+	const threshold = 5
+	rnd := rand.New(rand.NewSource(4))
+	incremental, err := NewIndexVector(WithNeighborsThreshold(threshold))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	list := make([]*Vector, 0)
+	for i := 0; i < 200; i++ {
+		v := NewVector(uint32(i), []float64{rnd.Float64() * 50, rnd.Float64() * 50}, nil)
+		if err = incremental.Add(v); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		list = append(list, v)
+	}
+	if err = incremental.Add(list[0]); err != ErrVectorExists {
+		t.Fatalf(`expected ErrVectorExists`)
+	}
+	for i := 0; i < 50; i++ {
+		if err = incremental.Remove(uint32(i)); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+	}
+	if err = incremental.Remove(1); err != ErrVectorNotFound {
+		t.Fatalf(`expected ErrVectorNotFound`)
+	}
+	list = list[50:]
+	for i := 0; i < 50; i++ {
+		list[i] = NewVector(list[i].Id, []float64{rnd.Float64() * 50, rnd.Float64() * 50}, nil)
+		if err = incremental.Update(list[i]); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+	}
+
+	fitted, err := NewIndexVector(WithNeighborsThreshold(threshold))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = fitted.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(incremental.itemsOrderZ) != len(fitted.itemsOrderZ) {
+		t.Fatalf(`len not equals %d, %d`, len(fitted.itemsOrderZ), len(incremental.itemsOrderZ))
+	}
+	for i, item := range incremental.itemsOrderZ {
+		if item.z != fitted.itemsOrderZ[i].z {
+			t.Fatalf(`order z broken at %d`, i)
+		}
+	}
+	for _, v := range list {
+		a, err := incremental.Neighbors(v.Id)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		b, err := fitted.Neighbors(v.Id)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		if len(a) != len(b) {
+			t.Fatalf(`id %d: neighbors %d != %d`, v.Id, len(a), len(b))
+		}
+	}
+}
+
 func TestIndexVector_Search(t *testing.T) {
 
 	iv, err := NewIndexVector()
@@ -0,0 +1,125 @@
+package word_index
+
+import (
+	"container/heap"
+	"sort"
+)
+
+type vectorCandidate struct {
+	item   *indexVectorItem
+	vector *Vector
+	row    int
+	dist   float64
+}
+
+// candidateHeap is a max-heap by distance, the root is the worst of kept candidates.
+type candidateHeap []vectorCandidate
+
+func (h candidateHeap) Len() int            { return len(h) }
+func (h candidateHeap) Less(i, j int) bool  { return h[i].dist > h[j].dist }
+func (h candidateHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x interface{}) { *h = append(*h, x.(vectorCandidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// newCandidateHeap returns heap for k of total candidates, k is capped by total
+// so capacity never depends on caller's k alone.
+func newCandidateHeap(k, total int) candidateHeap {
+	if k > total {
+		k = total
+	}
+	return make(candidateHeap, 0, k)
+}
+
+// push keeps at most k candidates with the smallest distances.
+func (h *candidateHeap) push(c vectorCandidate, k int) {
+	if h.Len() < k {
+		heap.Push(h, c)
+	} else if c.dist < (*h)[0].dist {
+		(*h)[0] = c
+		heap.Fix(h, 0)
+	}
+}
+
+// sorted returns candidates ordered by distance ascending.
+func (h candidateHeap) sorted() []vectorCandidate {
+	sort.Slice(h, func(i, j int) bool {
+		return h[i].dist < h[j].dist
+	})
+	return h
+}
+
+// SearchKNN returns k vectors nearest to v by the index metric, closest first.
+// With product quantizer distances are estimated from codes and, if originals are kept,
+// the best candidates are re-ranked by exact distance.
+func (iv *IndexVector) SearchKNN(v []float64, k int) ([]*Vector, error) {
+	if k <= 0 {
+		return []*Vector{}, nil
+	}
+	candidates, err := iv.searchKNN(v, k)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Vector, len(candidates))
+	for i, c := range candidates {
+		result[i] = c.item.i
+	}
+	return result, nil
+}
+
+func (iv *IndexVector) distance(a, b []float64) float64 {
+	if iv.metric == nil {
+		return distEuclidean(a, b)
+	}
+	return iv.metric.Distance(a, b)
+}
+
+func (iv *IndexVector) searchKNN(v []float64, k int) ([]vectorCandidate, error) {
+	return iv.searchKNNItems(iv.itemsOrderZ, v, k)
+}
+
+// searchKNNItems returns k items nearest to v among items.
+func (iv *IndexVector) searchKNNItems(items []*indexVectorItem, v []float64, k int) ([]vectorCandidate, error) {
+	if k > len(items) {
+		k = len(items)
+	}
+	if iv.pq == nil {
+		h := newCandidateHeap(k, len(items))
+		for _, item := range items {
+			if len(item.i.V) != len(v) {
+				return nil, ErrDimensionMismatch
+			}
+			h.push(vectorCandidate{item: item, dist: iv.distance(item.i.V, v)}, k)
+		}
+		return h.sorted(), nil
+	}
+
+	table, err := iv.pq.DistanceTable(v)
+	if err != nil {
+		return nil, err
+	}
+	n := k
+	if iv.pqRerank > 0 {
+		n = len(items)
+		if k <= len(items)/iv.pqRerank {
+			n = k * iv.pqRerank
+		}
+	}
+	h := newCandidateHeap(n, len(items))
+	for _, item := range items {
+		h.push(vectorCandidate{item: item, dist: AsymmetricDistance(table, item.code)}, n)
+	}
+	if iv.pqRerank == 0 {
+		return h.sorted(), nil
+	}
+
+	exact := newCandidateHeap(k, len(h))
+	for _, c := range h {
+		exact.push(vectorCandidate{item: c.item, dist: iv.distance(c.item.i.V, v)}, k)
+	}
+	return exact.sorted(), nil
+}
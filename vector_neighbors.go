@@ -0,0 +1,160 @@
+package word_index
+
+import (
+	"math"
+	"sort"
+)
+
+// neighborsGridDim limits the number of coordinates used for grid bucketing,
+// a point is compared with 3^neighborsGridDim cells at most.
+const neighborsGridDim = 3
+
+type neighborsCell [neighborsGridDim]int64
+
+// buildNeighbors links items closer than threshold. Items are bucketed into a grid
+// with cell size threshold, so only pairs from adjacent cells are compared.
+// Cells are processed by workers goroutines, each one writes only to items of its cells.
+func buildNeighbors(items []*indexVectorItem, threshold float64, workers int) {
+	grid := make(map[neighborsCell][]*indexVectorItem)
+	cells := make([]neighborsCell, 0)
+	for _, item := range items {
+		item.neighbors = make([]*indexVectorItem, 0)
+		cell := makeNeighborsCell(item.i.V, threshold)
+		if _, ok := grid[cell]; !ok {
+			cells = append(cells, cell)
+		}
+		grid[cell] = append(grid[cell], item)
+	}
+
+	parallelRange(len(cells), workers, func(low, high int) error {
+		for _, cell := range cells[low:high] {
+			linkCellNeighbors(grid, cell, threshold)
+		}
+		return nil
+	})
+}
+
+func linkCellNeighbors(grid map[neighborsCell][]*indexVectorItem, cell neighborsCell, threshold float64) {
+	bucket := grid[cell]
+	forEachAdjacentCell(cell, func(adjacent neighborsCell) {
+		other, ok := grid[adjacent]
+		if !ok {
+			return
+		}
+		for _, a := range bucket {
+			for _, b := range other {
+				if a == b {
+					continue
+				}
+				if a.i.DistEuclidean(b.i) <= threshold {
+					a.neighbors = append(a.neighbors, b)
+				}
+			}
+		}
+	})
+}
+
+func makeNeighborsCell(v []float64, threshold float64) neighborsCell {
+	cell := neighborsCell{}
+	for i := 0; i < len(v) && i < neighborsGridDim; i++ {
+		if threshold > 0 {
+			cell[i] = int64(math.Floor(v[i] / threshold))
+		}
+	}
+	return cell
+}
+
+func forEachAdjacentCell(cell neighborsCell, fn func(neighborsCell)) {
+	var walk func(dim int, c neighborsCell)
+	walk = func(dim int, c neighborsCell) {
+		if dim == neighborsGridDim {
+			fn(c)
+			return
+		}
+		for d := int64(-1); d <= 1; d++ {
+			next := c
+			next[dim] += d
+			walk(dim+1, next)
+		}
+	}
+	walk(0, cell)
+}
+
+// Neighbors returns vectors linked with the vector id by the neighbor graph ordered by id.
+func (iv *IndexVector) Neighbors(id uint32) ([]*Vector, error) {
+	if iv.neighborsThreshold == 0 {
+		return nil, ErrNeighborsDisabled
+	}
+	item, ok := iv.itemsMap[id]
+	if !ok {
+		return nil, ErrVectorNotFound
+	}
+	result := make([]*Vector, len(item.neighbors))
+	for i, n := range item.neighbors {
+		result[i] = n.i
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+	return result, nil
+}
+
+// SearchRadius returns vectors with Euclidean distance to v not greater than r.
+// When v has the z-code of an indexed vector and the ball fits into its neighborhood,
+// only its neighbors are checked, otherwise the box around the ball is scanned.
+func (iv *IndexVector) SearchRadius(v []float64, r float64) ([]*Vector, error) {
+	if r < 0 {
+		return []*Vector{}, nil
+	}
+	if iv.neighborsThreshold != 0 && r < iv.neighborsThreshold {
+		if center, d := iv.nearestSameZ(v); center != nil && d+r <= iv.neighborsThreshold {
+			result := make([]*Vector, 0)
+			if d <= r {
+				result = append(result, center.i)
+			}
+			buf := iv.vectorBuffer()
+			for _, n := range center.neighbors {
+				if distEuclidean(iv.vector(n, buf), v) <= r {
+					result = append(result, n.i)
+				}
+			}
+			return result, nil
+		}
+	}
+
+	min := make([]float64, len(v))
+	max := make([]float64, len(v))
+	for i, x := range v {
+		min[i], max[i] = x-r, x+r
+	}
+	items, err := iv.searchBox(min, max)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Vector, 0)
+	buf := iv.vectorBuffer()
+	for _, item := range items {
+		if distEuclidean(iv.vector(item, buf), v) <= r {
+			result = append(result, item.i)
+		}
+	}
+	return result, nil
+}
+
+// nearestSameZ returns the indexed item closest to v among items with the z-code of v,
+// it costs a binary search unlike a box scan.
+func (iv *IndexVector) nearestSameZ(v []float64) (*indexVectorItem, float64) {
+	z := ZOrderCurveFloat64(v)
+	var (
+		nearest *indexVectorItem
+		best    = math.MaxFloat64
+	)
+	buf := iv.vectorBuffer()
+	for low := iv.lowerBoundZ(z, 0); low < len(iv.itemsOrderZ) && iv.itemsOrderZ[low].z == z; low++ {
+		item := iv.itemsOrderZ[low]
+		if dist := distEuclidean(iv.vector(item, buf), v); dist < best {
+			nearest, best = item, dist
+		}
+	}
+	return nearest, best
+}
@@ -0,0 +1,154 @@
+package word_index
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestIndexVector_Neighbors(t *testing.T) {
+	iv, err := NewIndexVector(WithNeighborsThreshold(1.5))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	err = iv.Fit([]*Vector{
+		{Id: 1, V: []float64{1, 1}},
+		{Id: 2, V: []float64{1, 2}},
+		{Id: 3, V: []float64{2, 2}},
+		{Id: 4, V: []float64{-1, -1}},
+		{Id: 5, V: []float64{101, 102}},
+	})
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+
+	list, err := iv.Neighbors(1)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(list) != 2 {
+		t.Fatalf(`len not equals 2, %d`, len(list))
+	}
+	if list[0].Id != 2 || list[1].Id != 3 {
+		t.Fatalf(`neighbors must be ordered by id`)
+	}
+	list, err = iv.Neighbors(5)
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if len(list) != 0 {
+		t.Fatalf(`len not equals 0, %d`, len(list))
+	}
+	if _, err = iv.Neighbors(10); err != ErrVectorNotFound {
+		t.Fatalf(`expected ErrVectorNotFound`)
+	}
+
+	iv, _ = NewIndexVector()
+	if _, err = iv.Neighbors(1); err != ErrNeighborsDisabled {
+		t.Fatalf(`expected ErrNeighborsDisabled`)
+	}
+	if _, err = NewIndexVector(WithNeighborsThreshold(-1)); err != ErrInvalidThreshold {
+		t.Fatalf(`expected ErrInvalidThreshold`)
+	}
+}
+
+func TestIndexVector_NeighborsGrid(t *testing.T) {
+	const threshold = 7.5
+	rnd := rand.New(rand.NewSource(2))
+	list := make([]*Vector, 300)
+	for i := range list {
+		v := make([]float64, 5)
+		for j := range v {
+			v[j] = rnd.Float64()*100 - 50
+		}
+		list[i] = NewVector(uint32(i), v, nil)
+	}
+	iv, err := NewIndexVector(WithNeighborsThreshold(threshold))
+	if err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	if err = iv.Fit(list); err != nil {
+		t.Fatalf(`%s`, err.Error())
+	}
+	for _, a := range list {
+		neighbors, err := iv.Neighbors(a.Id)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		count := 0
+		for _, b := range list {
+			if a != b && a.DistEuclidean(b) <= threshold {
+				count++
+			}
+		}
+		if count != len(neighbors) {
+			t.Fatalf(`id %d: neighbors %d != %d`, a.Id, len(neighbors), count)
+		}
+	}
+}
+
+func TestIndexVector_SearchRadius(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	list := make([]*Vector, 400)
+	for i := range list {
+		list[i] = NewVector(uint32(i), []float64{rnd.Float64() * 20, rnd.Float64() * 20}, nil)
+	}
+	for _, threshold := range []float64{0, 3} {
+		var opts []IndexVectorOption
+		if threshold > 0 {
+			opts = append(opts, WithNeighborsThreshold(threshold))
+		}
+		iv, err := NewIndexVector(opts...)
+		if err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		if err = iv.Fit(list); err != nil {
+			t.Fatalf(`%s`, err.Error())
+		}
+		for n := 0; n < 100; n++ {
+			v := []float64{rnd.Float64() * 20, rnd.Float64() * 20}
+			if n%2 == 0 {
+				v = list[n].V
+			}
+			r := rnd.Float64() * 2
+			found, err := iv.SearchRadius(v, r)
+			if err != nil {
+				t.Fatalf(`%s`, err.Error())
+			}
+			count := 0
+			for _, item := range list {
+				if distEuclidean(item.V, v) <= r {
+					count++
+				}
+			}
+			if count != len(found) {
+				t.Fatalf(`threshold %.1f: found %d != %d`, threshold, len(found), count)
+			}
+		}
+	}
+}
+
+func BenchmarkIndexVector_SearchRadius(b *testing.B) {
+	rnd := rand.New(rand.NewSource(4))
+	list := make([]*Vector, 50000)
+	for i := range list {
+		list[i] = NewVector(uint32(i), []float64{rnd.Float64() * 1000, rnd.Float64() * 1000}, nil)
+	}
+	for _, threshold := range []float64{0, 5} {
+		var opts []IndexVectorOption
+		if threshold > 0 {
+			opts = append(opts, WithNeighborsThreshold(threshold))
+		}
+		iv, _ := NewIndexVector(opts...)
+		iv.Fit(list)
+		b.Run(fmt.Sprintf(`threshold %.0f`, threshold), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				v := list[i%len(list)].V
+				if i%2 == 0 {
+					v = []float64{v[0] + 0.1, v[1]}
+				}
+				iv.SearchRadius(v, 0.5)
+			}
+		})
+	}
+}